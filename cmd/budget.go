@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/praneeth-ayla/autocommenter/internal/ai"
+	"github.com/praneeth-ayla/autocommenter/internal/config"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var budgetFlags ai.Limits // Flags capping the tokens, cost and requests of a run
+
+// addBudgetFlags registers --max-tokens, --max-cost and --max-requests on a generation command.
+func addBudgetFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&budgetFlags.MaxTokens, "max-tokens", 0, "Stop before the run uses more than this many tokens")
+	cmd.Flags().Float64Var(&budgetFlags.MaxCost, "max-cost", 0, "Stop before the run costs more than this many US dollars")
+	cmd.Flags().IntVar(&budgetFlags.MaxRequests, "max-requests", 0, "Stop before the run sends more than this many requests")
+}
+
+// resolveLimits picks each limit from its flag, then project config. Zero means no limit.
+func resolveLimits(cfg *config.ProjectConfig) ai.Limits {
+	limits := ai.Limits{
+		MaxTokens:   cfg.Budget.MaxTokens,
+		MaxCost:     cfg.Budget.MaxCost,
+		MaxRequests: cfg.Budget.MaxRequests,
+	}
+	if budgetFlags.MaxTokens > 0 {
+		limits.MaxTokens = budgetFlags.MaxTokens
+	}
+	if budgetFlags.MaxCost > 0 {
+		limits.MaxCost = budgetFlags.MaxCost
+	}
+	if budgetFlags.MaxRequests > 0 {
+		limits.MaxRequests = budgetFlags.MaxRequests
+	}
+	return limits
+}
+
+// withBudget wraps provider so that requests past the run's limits are refused.
+// Spending is read from meter, which must also be the meter in each request's context.
+func withBudget(provider ai.Provider, cfg *config.ProjectConfig, meter *usage.Meter) (ai.Provider, *ai.Budget) {
+	budget := ai.NewBudget(resolveLimits(cfg), meter, config.Prices())
+	return ai.WithBudget(provider, budget), budget
+}
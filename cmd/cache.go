@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/praneeth-ayla/autocommenter/internal/cache"
+	"github.com/praneeth-ayla/autocommenter/internal/config"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	noCache   bool // Flag sending every request to the provider instead of reusing cached responses
+	cacheJSON bool // Flag printing cache stats as JSON
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the local cache of provider responses",
+	Long: `Generation commands keep provider responses in a local cache, keyed by the
+provider, model, system instruction, prompt and generation parameters. Running
+a command again on unchanged input reuses the earlier response instead of
+sending a new request. Responses expire after a TTL (30 days by default), and
+the least recently used ones are evicted once the cache passes its size limit
+(256 MB by default). Pass --no-cache to a generation command to bypass it.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the size and age of the response cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		responses, err := config.ResponseCache()
+		if err != nil {
+			return err
+		}
+		stats, err := responses.Stats()
+		if err != nil {
+			return fmt.Errorf("response cache: %w", err)
+		}
+		if cacheJSON {
+			return printJSON(stats)
+		}
+
+		fmt.Println("Cache:", stats.Dir)
+		fmt.Printf("Entries: %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size: %s of %s\n", formatBytes(stats.Size), formatBytes(stats.MaxSize))
+		fmt.Println("TTL:", formatTTL(stats.TTL))
+		if stats.Entries > 0 {
+			fmt.Println("Least recently used:", stats.Oldest.Local().Format(time.DateTime))
+			fmt.Println("Most recently used:", stats.Newest.Local().Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached response",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		responses, err := config.ResponseCache()
+		if err != nil {
+			return err
+		}
+		n, err := responses.Clear()
+		if err != nil {
+			return fmt.Errorf("response cache: %w", err)
+		}
+		fmt.Printf("Removed %d cached responses\n", n)
+		return nil
+	},
+}
+
+func init() {
+	cacheStatsCmd.SilenceUsage = true
+	cacheClearCmd.SilenceUsage = true
+	cacheStatsCmd.Flags().BoolVar(&cacheJSON, "json", false, "Print the stats as JSON")
+
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+// addCacheFlag registers --no-cache on a generation command.
+func addCacheFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Send every request to the provider instead of reusing cached responses")
+}
+
+// runContext returns the base context of a generation run. Its requests are
+// metered by meter and, unless --no-cache is set, answered from the response
+// cache when an identical request was made before.
+func runContext(meter *usage.Meter) context.Context {
+	ctx := usage.WithMeter(context.Background(), meter)
+	if noCache {
+		return ctx
+	}
+	responses, err := config.ResponseCache()
+	if err != nil {
+		fmt.Println("response cache disabled:", err)
+		return ctx
+	}
+	return cache.WithCache(ctx, responses)
+}
+
+// formatBytes renders a size in bytes, KB or MB.
+func formatBytes(n int64) string {
+	switch {
+	case n < 1<<10:
+		return fmt.Sprintf("%d B", n)
+	case n < 1<<20:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
+
+// formatTTL renders a TTL in whole days when it is one.
+func formatTTL(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
@@ -1,16 +1,23 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/praneeth-ayla/autocommenter/internal/ai"
 	"github.com/praneeth-ayla/autocommenter/internal/ai/providerutil"
 	"github.com/praneeth-ayla/autocommenter/internal/config"
 	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/gosrc"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
+	"github.com/praneeth-ayla/autocommenter/internal/progress"
 	"github.com/praneeth-ayla/autocommenter/internal/prompt"
 	"github.com/praneeth-ayla/autocommenter/internal/scanner"
 	"github.com/praneeth-ayla/autocommenter/internal/ui"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
 	"github.com/spf13/cobra"
 )
 
@@ -34,82 +41,295 @@ var genCommentsCmd = &cobra.Command{
 	RunE:  runGenerateComments,
 }
 
+var checkCommentsCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report exported Go declarations without doc comments",
+	Long: `Parse Go files and list exported declarations that have no doc comment.
+No AI provider is contacted. Exits with an error when anything is missing.
+
+Examples:
+  autocommenter comments check
+  autocommenter comments check --staged --hunks
+  autocommenter comments check --since main
+`,
+	RunE: runCheckComments,
+}
+
+// defaultCommentStyle is used where no style is chosen interactively.
+const defaultCommentStyle = "docstring"
+
+var (
+	explainFilter bool          // Flag to print filter decisions instead of generating
+	contextBudget int           // Flag overriding the per-file context token budget
+	genScope      gitScope      // Git restriction for comments gen
+	genCommit     commitOptions // Branch and commit handling for comments gen
+	checkScope    gitScope      // Git restriction for comments check
+)
+
 func init() {
 	genCommentsCmd.SilenceUsage = true
 	// genCommentsCmd.SilenceErrors = true
 
+	genCommentsCmd.Flags().BoolVar(&explainFilter, "explain", false, "Print why each file was included or skipped, then exit")
+	addGitScopeFlags(genCommentsCmd, &genScope, false) // Generation rewrites whole files, so --hunks would not limit it.
+	addCommitFlags(genCommentsCmd, &genCommit)
+	genCommentsCmd.Flags().IntVar(&contextBudget, "context-budget", 0, "Approximate tokens of project context sent with each file (-1 for all)")
+	addEstimateFlag(genCommentsCmd)
+	addBudgetFlags(genCommentsCmd)
+	addCacheFlag(genCommentsCmd)
+
+	checkCommentsCmd.SilenceUsage = true
+	addGitScopeFlags(checkCommentsCmd, &checkScope, true)
+
 	rootCmd.AddCommand(commentsCmd)
 	commentsCmd.AddCommand(genCommentsCmd)
+	commentsCmd.AddCommand(checkCommentsCmd)
 }
 
 func runGenerateComments(cmd *cobra.Command, args []string) error {
-	providerName, _ := config.GetProvider()
-	provider, err := ai.NewProvider(providerName)
+	rootPath := scanner.GetProjectRoot()
+	projectCfg, err := config.LoadProject(rootPath)
 	if err != nil {
-		return fmt.Errorf("provider init: %w", err)
+		return fmt.Errorf("project config: %w", err)
 	}
 
-	commentStyle, err := ui.SelectOne("Select comment style:", prompt.Styles)
+	fmt.Println("Scanning project files...")
+	files, err := scanner.Scan(rootPath)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+
+	files, err = genScope.apply(rootPath, files)
 	if err != nil {
 		return err
 	}
 
-	rootPath := scanner.GetProjectRoot()
-	fmt.Println("Scanning project files...")
-	files, err := scanner.Scan(rootPath)
+	rules := filterRules(projectCfg)
+	if explainFilter {
+		printFilterDecisions(scanner.ExplainFilter(rootPath, files, rules))
+		return nil
+	}
+
+	provider, err := loadProvider()
 	if err != nil {
-		return fmt.Errorf("scan failed: %w", err)
+		return fmt.Errorf("provider init: %w", err)
+	}
+
+	pol, gate, err := loadPolicy(rootPath, provider)
+	if err != nil {
+		return err
+	}
+	if provider, err = withPolicy(provider, pol, rootPath, commandName(cmd)); err != nil {
+		return err
 	}
 
-	filteredFiles := scanner.FilterFilesNeedingComments(files)
+	filteredFiles := withholdFiles(rootPath, scanner.FilterFilesNeedingComments(rootPath, files, rules), gate)
 	if len(filteredFiles) == 0 {
 		fmt.Println("No files need comments")
 		return nil
 	}
 
 	fmt.Printf("Found %d files needing comments\n", len(filteredFiles))
+	if estimateOnly {
+		return estimateComments(provider, rootPath, filteredFiles, resolveContextBudget(projectCfg))
+	}
+
+	commentStyle, err := ui.SelectOne("Select comment style:", prompt.Styles)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println("Loading project context...")
-	ctxMap, err := contextstore.Load()
+	store, err := contextstore.Load()
 	if err != nil {
 		// Inform user to generate context if none is found.
 		return fmt.Errorf("no project context found. Run: autocommenter context gen")
 	}
 
-	allCtxSlice := contextstore.MapToSlice(ctxMap)
+	hierarchy := store.Hierarchy()
+	budget := resolveContextBudget(projectCfg)
+
+	if err := genCommit.prepare(rootPath, "comments"); err != nil {
+		return err
+	}
 
 	fmt.Println("Generating comments (this may take a while)...")
 	successCount, errorCount := 0, 0
 
-	for i, file := range filteredFiles {
-		fmt.Printf("\n[%d/%d] %s\n", i+1, len(filteredFiles), file.Path)
-		if err := processFile(file, provider, allCtxSlice, commentStyle); err != nil {
-			fmt.Printf("  ✖ error: %v\n", err)
+	meter := usage.NewMeter(commandName(cmd))
+	provider, redactor := withRedaction(provider)
+	provider, spend := withBudget(provider, projectCfg, meter)
+	runCtx := runContext(meter)
+	var skipped []string // Files left untouched once the budget ran out.
+
+	progressBar := progress.New("comments", len(filteredFiles))
+	for _, file := range filteredFiles {
+		rel := relPath(rootPath, file.Path)
+		if spend.Err() != nil {
+			skipped = append(skipped, rel)
+			continue
+		}
+		task := progressBar.Start(rel)
+		ctx := usage.WithFile(progress.WithTask(runCtx, task), rel)
+		err := processFile(ctx, file, provider, hierarchy, commentStyle, budget)
+		task.Done(err)
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			skipped = append(skipped, rel)
+		} else if err != nil {
 			errorCount++
 		} else {
-			fmt.Println("  ✓ updated")
 			successCount++
 		}
 	}
+	progressBar.Stop()
 
 	fmt.Println("\n" + strings.Repeat("─", 50))
-	fmt.Printf("Summary: %d succeeded, %d failed\n", successCount, errorCount)
+	fmt.Printf("Summary: %d succeeded, %d failed", successCount, errorCount)
+	if len(skipped) > 0 {
+		fmt.Printf(", %d skipped", len(skipped))
+	}
+	fmt.Println()
+	printDiffSection("skipped", skipped)
+	printRedactions(redactor, rootPath)
+	finishUsage(meter, rootPath)
 
+	subject := fmt.Sprintf("docs: add generated comments (%s style)", commentStyle)
+	if err := genCommit.finish(rootPath, subject, "Style: "+commentStyle); err != nil {
+		return err
+	}
+
+	if err := spend.Err(); err != nil {
+		return fmt.Errorf("stopped early, %w; %d files skipped", err, len(skipped))
+	}
 	if errorCount > 0 {
 		return fmt.Errorf("completed with %d errors", errorCount)
 	}
 	return nil
 }
 
-func processFile(file scanner.Info, provider ai.Provider, ctx []contextstore.FileDetails, style string) error {
+func runCheckComments(cmd *cobra.Command, args []string) error {
+	rootPath := scanner.GetProjectRoot()
+	projectCfg, err := config.LoadProject(rootPath)
+	if err != nil {
+		return fmt.Errorf("project config: %w", err)
+	}
+
+	files, err := scanner.Scan(rootPath)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	files, err = checkScope.apply(rootPath, files)
+	if err != nil {
+		return err
+	}
+	files = scanner.FilterFilesNeedingComments(rootPath, files, filterRules(projectCfg))
+
+	reports, checked := findMissingDocs(rootPath, files, &checkScope)
+	missing := printMissingDocs(reports)
+
+	if missing > 0 {
+		return fmt.Errorf("%d exported declarations missing doc comments in %d files checked", missing, checked)
+	}
+	fmt.Printf("All exported declarations documented (%d files checked)\n", checked)
+	return nil
+}
+
+// docReport lists the undocumented exported declarations of one file.
+type docReport struct {
+	File    scanner.Info
+	Rel     string
+	Missing []gosrc.Decl
+}
+
+// findMissingDocs parses the Go files among files and reports those with undocumented
+// exported declarations. It also returns how many files were parsed successfully.
+func findMissingDocs(rootPath string, files []scanner.Info, scope *gitScope) ([]docReport, int) {
+	var reports []docReport
+	checked := 0
+
+	for _, f := range files {
+		if filepath.Ext(f.Path) != ".go" {
+			continue // Only Go sources can be checked without a model.
+		}
+		decls, err := scope.touchedDecls(rootPath, f.Path)
+		if err != nil {
+			fmt.Printf("%s: parse error: %v\n", f.Path, err)
+			continue
+		}
+		checked++
+
+		if missing := gosrc.MissingDocs(decls); len(missing) > 0 {
+			rel, _ := filepath.Rel(rootPath, f.Path)
+			reports = append(reports, docReport{File: f, Rel: filepath.ToSlash(rel), Missing: missing})
+		}
+	}
+	return reports, checked
+}
+
+// printMissingDocs prints one line per undocumented declaration and returns the count.
+func printMissingDocs(reports []docReport) int {
+	count := 0
+	for _, r := range reports {
+		for _, d := range r.Missing {
+			fmt.Printf("%s:%d: %s %s is missing a doc comment\n", r.Rel, d.Line, d.Kind, d.Name)
+			count++
+		}
+	}
+	return count
+}
+
+// resolveContextBudget picks the context budget from the flag, then project config, then the default.
+func resolveContextBudget(cfg *config.ProjectConfig) int {
+	switch {
+	case contextBudget != 0:
+		return contextBudget
+	case cfg.Context.Budget != 0:
+		return cfg.Context.Budget
+	}
+	return contextstore.DefaultContextBudget
+}
+
+// estimateComments prints what commenting files would send without calling the provider.
+func estimateComments(provider ai.Provider, rootPath string, files []scanner.Info, budget int) error {
+	notes := []string{"assumes the " + defaultCommentStyle + " style; follow-up requests that repair output which changed code are not included"}
+	var hierarchy contextstore.Hierarchy
+	if store, err := loadContext(); err == nil {
+		hierarchy = store.Hierarchy()
+	} else {
+		notes = append(notes, "no project context found; prompts will be larger once 'context gen' has run")
+	}
+
+	model := ai.ModelFor(provider, ai.TaskComments)
+	meter := usage.NewMeter("comments gen")
+	for _, file := range files {
+		fd := scanner.LoadSingle(file)
+		rel := relPath(rootPath, file.Path)
+		u, err := ai.EstimateComments(provider, fd.Content, hierarchy.Select(rel, fd.Content, budget), defaultCommentStyle)
+		if err != nil {
+			return err
+		}
+		meter.Add(rel, model, u)
+	}
+
+	printEstimate(meter, notes...)
+	return nil
+}
+
+func processFile(ctx context.Context, file scanner.Info, provider ai.Provider, all contextstore.Hierarchy, style string, budget int) error {
 	fd := scanner.LoadSingle(file)
 
+	// Send only the context most relevant to this file.
+	selected := all.Select(relPath(scanner.GetProjectRoot(), file.Path), fd.Content, budget)
+
+	// The egress policy is applied to the file's real path.
+	ctx = policy.WithSource(ctx, file.Path)
+
 	// Use DoWithRetry for AI calls to handle transient errors.
 	commented, err := providerutil.DoWithRetry[string](
 		providerutil.MaxRetryAttempts,
 		providerutil.PerRequestTimeout,
 		func() (string, error) {
-			return provider.GenerateComments(fd.Content, ctx, style)
+			return provider.GenerateComments(ctx, fd.Content, selected, style)
 		},
 	)
 	if err != nil {
@@ -118,3 +338,26 @@ func processFile(file scanner.Info, provider ai.Provider, ctx []contextstore.Fil
 
 	return scanner.WriteFile(file.Path, commented)
 }
+
+// filterRules converts the project filter configuration into scanner rules.
+func filterRules(cfg *config.ProjectConfig) scanner.FilterRules {
+	return scanner.FilterRules{
+		Include:      cfg.Filter.Include,
+		Exclude:      cfg.Filter.Exclude,
+		IncludeTests: cfg.Filter.IncludeTests,
+	}
+}
+
+// printFilterDecisions lists every scanned file with the reason it was kept or skipped.
+func printFilterDecisions(decisions []scanner.Decision) {
+	included := 0
+	for _, d := range decisions {
+		mark := "skip"
+		if d.Included {
+			mark = "keep"
+			included++
+		}
+		fmt.Printf("%s  %s  (%s)\n", mark, d.Rel, d.Reason)
+	}
+	fmt.Printf("\n%d of %d files included\n", included, len(decisions))
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/praneeth-ayla/autocommenter/internal/git"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// commitOptions commits the files a command wrote on a dedicated branch.
+type commitOptions struct {
+	enabled    bool
+	branch     string
+	allowDirty bool
+}
+
+// addCommitFlags registers --commit, --branch and --allow-dirty.
+func addCommitFlags(c *cobra.Command, o *commitOptions) {
+	c.Flags().BoolVar(&o.enabled, "commit", false, "Commit written files on a new branch")
+	c.Flags().StringVar(&o.branch, "branch", "", "Branch name for --commit (implies --commit)")
+	c.Flags().BoolVar(&o.allowDirty, "allow-dirty", false, "Allow --commit with uncommitted changes in tracked files")
+}
+
+// active reports whether the run should end with a commit.
+func (o *commitOptions) active() bool {
+	return o.enabled || o.branch != ""
+}
+
+// prepare verifies the working tree and creates the branch. It must run before any file is written.
+func (o *commitOptions) prepare(rootPath, kind string) error {
+	if !o.active() {
+		return nil
+	}
+
+	clean, err := git.IsClean(rootPath)
+	if err != nil {
+		return fmt.Errorf("git status failed: %w", err)
+	}
+	if !clean && !o.allowDirty {
+		return fmt.Errorf("working tree has uncommitted changes; commit or stash them, or pass --allow-dirty")
+	}
+
+	if o.branch == "" {
+		o.branch = fmt.Sprintf("autocommenter/%s-%s", kind, time.Now().Format("20060102-150405"))
+	}
+	if err := git.CreateBranch(rootPath, o.branch); err != nil {
+		return fmt.Errorf("create branch: %w", err)
+	}
+	fmt.Println("Switched to branch", o.branch)
+	return nil
+}
+
+// finish commits the files recorded in the write log that belong to the project.
+func (o *commitOptions) finish(rootPath, subject string, details ...string) error {
+	if !o.active() {
+		return nil
+	}
+
+	var paths []string
+	for _, p := range scanner.Written() {
+		rel, err := filepath.Rel(rootPath, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue // Written outside the repository, e.g. the home directory.
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	if len(paths) == 0 {
+		fmt.Println("Nothing written, no commit created")
+		return nil
+	}
+
+	if err := git.Add(rootPath, paths...); err != nil {
+		return fmt.Errorf("stage files: %w", err)
+	}
+	changed, err := git.StagedPaths(rootPath, paths...)
+	if err != nil {
+		return fmt.Errorf("stage files: %w", err)
+	}
+	if len(changed) == 0 {
+		fmt.Println("Written files are unchanged, no commit created")
+		return nil
+	}
+
+	var msg strings.Builder
+	msg.WriteString(subject + "\n\n")
+	msg.WriteString("Generated by autocommenter.\n")
+	for _, d := range details {
+		msg.WriteString(d + "\n")
+	}
+	msg.WriteString("\nFiles:\n")
+	for _, p := range changed {
+		msg.WriteString("- " + p + "\n")
+	}
+
+	if err := git.Commit(rootPath, msg.String(), changed...); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	fmt.Printf("Committed %d files on branch %s\n", len(changed), o.branch)
+	return nil
+}
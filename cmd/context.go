@@ -4,15 +4,26 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/praneeth-ayla/autocommenter/internal/ai"
 	"github.com/praneeth-ayla/autocommenter/internal/ai/providerutil"
 	"github.com/praneeth-ayla/autocommenter/internal/config"
 	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/progress"
+	"github.com/praneeth-ayla/autocommenter/internal/prompt"
 	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
 	"github.com/spf13/cobra"
 )
 
@@ -37,91 +48,588 @@ var contextGenCmd = &cobra.Command{
 	Long: `Scan supported files in the project and store context data.
 This improves the quality of generated comments and readme later.
 
+Only new or changed files are sent to the provider; entries for
+deleted files are dropped. Use --force to regenerate everything.
+
+Each batch is saved as soon as it completes. If a run is interrupted or
+some files fail, --resume continues with just the files it did not save.
+
 Example:
   autocommenter context gen
+  autocommenter context gen --force
+  autocommenter context gen --resume
 `,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		providerName, _ := config.GetProvider()
-		provider, err := ai.NewProvider(providerName)
-		if err != nil {
-			fmt.Println("provider error:", err)
-			return err
-		}
-
-		rootPath := scanner.GetProjectRoot()
-		files, err := scanner.Scan(rootPath)
-		if err != nil {
-			fmt.Println("scan error:", err)
-			return fmt.Errorf("scan failed: %w", err)
-		}
-
-		fmt.Println("Generating Context")
-		if len(files) == 0 {
-			fmt.Println("No files found for context generation")
-			return nil
-		}
-
-		batches := scanner.BatchByLines(files, 500)
-		allContext := make(map[string]contextstore.FileDetails)
-
-		var wg sync.WaitGroup
-		var mu sync.Mutex
-
-		for _, batch := range batches {
-			wg.Add(1)
-			go func(b []scanner.Info) {
-				defer wg.Done()
-
-				batchData := scanner.Load(b)
-
-				ctxBatch, err := providerutil.DoWithRetry[[]contextstore.FileDetails](
-					providerutil.MaxRetryAttempts,
-					providerutil.PerRequestTimeout,
-					func() ([]contextstore.FileDetails, error) {
-						return provider.GenerateContextBatch(batchData)
-					},
-				)
-				if err != nil {
-					fmt.Println("context batch error:", err)
-					return
-				}
+	RunE: runGenerateContext,
+}
+
+var (
+	contextScope  gitScope // Git restriction for context gen
+	contextForce  bool     // Flag to regenerate unchanged files too
+	contextResume bool     // Flag to continue the last unfinished run
+	batchInput    int      // Flag overriding the estimated prompt tokens per request
+	batchOutput   int      // Flag overriding the expected response tokens per request
+)
+
+func init() {
+	contextGenCmd.SilenceUsage = true
+	// contextGenCmd.SilenceErrors = true
+
+	addGitScopeFlags(contextGenCmd, &contextScope, false)
+	contextGenCmd.Flags().BoolVar(&contextForce, "force", false, "Regenerate context for unchanged files too")
+	contextGenCmd.Flags().BoolVar(&contextResume, "resume", false, "Continue the last unfinished run, generating only the files it has not saved")
+	contextGenCmd.MarkFlagsMutuallyExclusive("force", "resume")
+	contextGenCmd.Flags().IntVar(&batchInput, "batch-input-tokens", 0, "Estimated prompt tokens per request (default 24000)")
+	contextGenCmd.Flags().IntVar(&batchOutput, "batch-output-tokens", 0, "Expected response tokens per request (default 8000)")
+	addEstimateFlag(contextGenCmd)
+	addBudgetFlags(contextGenCmd)
+	addCacheFlag(contextGenCmd)
 
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextGenCmd)
+}
+
+func runGenerateContext(cmd *cobra.Command, args []string) error {
+	provider, err := loadProvider()
+	if err != nil {
+		fmt.Println("provider error:", err)
+		return err
+	}
+
+	rootPath := scanner.GetProjectRoot()
+	projectCfg, err := config.LoadProject(rootPath)
+	if err != nil {
+		return fmt.Errorf("project config: %w", err)
+	}
+
+	allFiles, err := scanner.Scan(rootPath)
+	if err != nil {
+		fmt.Println("scan error:", err)
+		return fmt.Errorf("scan failed: %w", err)
+	}
+
+	files, err := contextScope.apply(rootPath, allFiles)
+	if err != nil {
+		return err
+	}
+	pol, gate, err := loadPolicy(rootPath, provider)
+	if err != nil {
+		return err
+	}
+	files = withholdFiles(rootPath, files, gate)
+	if provider, err = withPolicy(provider, pol, rootPath, commandName(cmd)); err != nil {
+		return err
+	}
+
+	stored, err := loadContext()
+	if errors.Is(err, contextstore.ErrNotFound) {
+		stored = contextstore.NewStore()
+	} else if err != nil {
+		return fmt.Errorf("context load failed: %w", err)
+	}
+
+	// A resumed run picks up exactly the files its checkpoint has not saved yet.
+	var resumeFrom map[string]bool
+	if contextResume {
+		checkpoint, err := contextstore.LoadCheckpoint()
+		if errors.Is(err, contextstore.ErrNotFound) {
+			return fmt.Errorf("no interrupted context generation to resume")
+		} else if err != nil {
+			return fmt.Errorf("checkpoint load failed: %w", err)
+		}
+		resumeFrom = make(map[string]bool)
+		for _, p := range checkpoint.Remaining() {
+			resumeFrom[p] = true
+		}
+		if checkpoint.Live() {
+			fmt.Fprintf(os.Stderr, "Warning: the run that wrote this checkpoint (pid %d on %s) may still be running; both runs will generate the same files\n", checkpoint.PID, checkpoint.Host)
+		}
+		fmt.Printf("Resuming run from %s: %d of %d files left\n", formatTime(checkpoint.StartedAt), len(resumeFrom), len(checkpoint.Pending))
+	} else if previous, err := contextstore.LoadCheckpoint(); err == nil && !estimateOnly {
+		if previous.Live() {
+			fmt.Fprintf(os.Stderr, "Warning: another run (pid %d on %s, started %s) may still be running; starting over discards its checkpoint, so it can no longer be resumed\n", previous.PID, previous.Host, formatTime(previous.StartedAt))
+		} else {
+			fmt.Println("Starting over; the unfinished previous run is discarded (its saved files are kept)")
+		}
+	}
+
+	// Hash every candidate and keep only those that are new or changed.
+	contents := make(map[string]scanner.Data, len(files))
+	for _, data := range scanner.Load(files) {
+		contents[data.Path] = data
+	}
+
+	hashes := make(map[string]string, len(files))
+	var pending []scanner.Data
+	var pendingPaths []string
+	unchanged := 0
+	for _, f := range files {
+		data, ok := contents[f.Path]
+		if !ok {
+			continue // Unreadable; scanner.Load already reported it.
+		}
+		rel := relPath(rootPath, f.Path)
+		hashes[rel] = contextstore.HashContent(data.Content)
+
+		switch {
+		case resumeFrom != nil:
+			if !resumeFrom[rel] {
+				unchanged++
+				continue
+			}
+		case !contextForce:
+			if old, ok := stored.Files[rel]; ok && old.Hash == hashes[rel] {
+				unchanged++
+				continue
+			}
+		}
+		pending = append(pending, data)
+		pendingPaths = append(pendingPaths, rel)
+	}
+
+	estimator := ai.EstimatorFor(provider)
+	batches := scanner.BatchByTokens(pending, resolveBatchLimits(projectCfg), func(d scanner.Data) (int, int) {
+		return estimator.Estimate(prompt.BuildFileContextPrompt(d.Label(), d.Content)), ai.ContextOutputTokens
+	})
+	if estimateOnly {
+		estimateContext(provider, rootPath, stored, batches, contents)
+		return nil
+	}
+
+	checkpoint := contextstore.NewCheckpoint(pendingPaths)
+	if len(pending) > 0 {
+		if err := contextstore.SaveCheckpoint(checkpoint); err != nil {
+			return fmt.Errorf("checkpoint save failed: %w", err)
+		}
+	}
+
+	fmt.Println("Generating Context")
+
+	meter := usage.NewMeter(commandName(cmd))
+	baseCtx := runContext(meter)
+	provider, redactor := withRedaction(provider)
+	provider, budget := withBudget(provider, projectCfg, meter)
+	progressBar := progress.New("context", len(batches))
+	var wg sync.WaitGroup
+	var mu sync.Mutex // Guards the counters, partial results and the checkpoint.
+	added, updated := 0, 0
+	partials := make(map[string][]contextstore.FileDetails) // Results for the parts of split files.
+	var outcome reconcileOutcome                            // How the provider's output matched the files sent.
+
+	for _, batch := range batches {
+		wg.Add(1)
+		go func(b []scanner.Data) {
+			defer wg.Done()
+
+			var batchPaths []string
+			for _, d := range b {
+				batchPaths = append(batchPaths, relPath(rootPath, d.Path))
+			}
+			label := batchLabel(rootPath, b)
+			task := progressBar.Start(label)
+			ctx := usage.WithFile(progress.WithTask(baseCtx, task), label)
+
+			ctxBatch, err := generateContextBatch(ctx, provider, b)
+			if err != nil {
+				task.Done(err)
+				reason := err.Error()
+				if errors.Is(err, ai.ErrBudgetExceeded) {
+					reason = "skipped, " + budget.Err().Error()
+				}
 				mu.Lock()
-				for _, item := range ctxBatch {
-					rel, err := filepath.Rel(rootPath, item.Path)
-					if err != nil || rel == "." { // Handle errors or if the file is at the root
-						item.Path = filepath.Clean(item.Path)
+				checkpoint.MarkFailed(reason, batchPaths...)
+				saveCheckpoint(checkpoint)
+				mu.Unlock()
+				return
+			}
+
+			// Keep only entries for the files sent, and ask again for any the model
+			// left out, one file per request.
+			rec := contextstore.Reconcile(b, ctxBatch)
+			retried := 0
+			if len(b) > 1 {
+				if len(rec.Missing) > 0 {
+					task.SetStatus(fmt.Sprintf("retrying %d missing", len(rec.Missing)))
+				}
+				for _, d := range rec.Missing {
+					retried++
+					if single, err := generateContextBatch(ctx, provider, []scanner.Data{d}); err == nil {
+						rec.Merge(contextstore.Reconcile([]scanner.Data{d}, single))
+					}
+				}
+			}
+
+			task.Done(nil)
+
+			mu.Lock()
+			defer mu.Unlock()
+			outcome.add(rootPath, rec, retried)
+			ctxBatch = rec.Files
+
+			// Parts are batched alone; the file is saved once every part has come back.
+			if part := b[0]; len(b) == 1 && part.Parts > 1 && len(ctxBatch) > 0 {
+				rel := batchPaths[0]
+				if partials[rel] == nil {
+					partials[rel] = make([]contextstore.FileDetails, part.Parts)
+				}
+				partials[rel][part.Part-1] = ctxBatch[0]
+				for _, p := range partials[rel] {
+					if p.Path == "" {
+						return
+					}
+				}
+				ctxBatch = []contextstore.FileDetails{contextstore.MergeParts(partials[rel])}
+			}
+
+			now := time.Now().UTC()
+			entries := make(map[string]contextstore.FileDetails, len(ctxBatch))
+			for _, item := range ctxBatch {
+				item.Path = relPath(rootPath, item.Path)
+				item.Hash = hashes[item.Path]
+				item.GeneratedAt = now
+				entry, warnings := withStaticFacts(rootPath, item, contents)
+				for _, w := range warnings {
+					progressBar.Println(w) // Batches finish concurrently; keep the live block intact.
+				}
+				entries[item.Path] = entry
+			}
+
+			// Save the batch right away so an interrupted run keeps it.
+			err = contextstore.Update(func(latest *contextstore.Store) error {
+				for path, item := range entries {
+					if _, ok := latest.Files[path]; ok {
+						updated++
 					} else {
-						item.Path = filepath.ToSlash(rel) // Use forward slashes for consistent paths
+						added++
 					}
+					latest.Files[path] = item
+				}
+				return nil
+			})
+			if err != nil {
+				progressBar.Println("context save error:", err)
+				checkpoint.MarkFailed("save failed: "+err.Error(), batchPaths...)
+				saveCheckpoint(checkpoint)
+				return
+			}
 
-					allContext[item.Path] = item
+			for _, path := range batchPaths {
+				if _, ok := entries[path]; ok {
+					checkpoint.MarkDone(path)
+				} else {
+					checkpoint.MarkFailed("not returned by the provider", path)
 				}
-				mu.Unlock()
+			}
+			saveCheckpoint(checkpoint)
+		}(batch)
+	}
+	wg.Wait()
+	progressBar.Stop()
+
+	// Finish against the latest saved context, which another run may have changed since it was loaded.
+	present := make(map[string]bool, len(allFiles))
+	for _, f := range allFiles {
+		present[relPath(rootPath, f.Path)] = true
+	}
+	modulePath, err := scanner.ModulePath(rootPath)
+	if err != nil {
+		fmt.Println("module path unavailable:", err)
+	}
+	removed := 0
+	saved := added+updated > 0
+	var overview contextstore.Hierarchy // Packages and module still needing summaries.
+	err = contextstore.Update(func(latest *contextstore.Store) error {
+		// Entries whose files no longer exist are dropped, even when only changed files are scanned.
+		for path := range latest.Files {
+			if !present[path] {
+				delete(latest.Files, path)
+				removed++
+			}
+		}
+
+		// Packages are regrouped from the file entries; their summaries are generated below.
+		overview = latest.RefreshPackages(modulePath)
+		if len(overview.Packages) == 0 {
+			overview.Module = nil // No Go packages to describe yet.
+		}
+
+		graph := buildImportGraph(rootPath, allFiles)
+		graphUpdated := !reflect.DeepEqual(latest.Graph, &graph)
+		latest.Graph = &graph
 
-			}(batch)
+		if removed == 0 && overview.Module == nil && !graphUpdated {
+			return contextstore.ErrSkipSave
 		}
-		wg.Wait()
+		saved = true
+		return nil
+	})
+	if err != nil {
+		fmt.Println("save error:", err)
+		return fmt.Errorf("context save failed: %w", err)
+	}
+
+	// The provider is called without holding the context file lock.
+	if overview.Module != nil {
+		refreshOverview(usage.WithFile(baseCtx, "overview"), provider, overview, modulePath)
+	}
 
-		if len(allContext) == 0 {
-			fmt.Println("No context generated after processing batches")
-			return nil
+	failed := len(checkpoint.Failed)
+	if failed == 0 {
+		if err := contextstore.RemoveCheckpoint(checkpoint); err != nil {
+			fmt.Println("checkpoint cleanup error:", err)
 		}
+	}
 
-		if err := contextstore.Save(allContext); err != nil {
-			fmt.Println("save error:", err)
-			return fmt.Errorf("context save failed: %w", err)
+	switch {
+	case !saved && failed == 0:
+		fmt.Printf("Context is up to date (%d unchanged)\n", unchanged)
+	case saved:
+		fmt.Printf("context generation completed: %d added, %d updated, %d removed, %d unchanged", added, updated, removed, unchanged)
+		if failed > 0 {
+			fmt.Printf(", %d failed", failed)
 		}
+		fmt.Println()
+	}
 
-		fmt.Println("context generation completed")
+	outcome.print()
+	printRedactions(redactor, rootPath)
+	finishUsage(meter, rootPath)
+
+	if failed > 0 {
+		printFailures(checkpoint.Failed)
+		if err := budget.Err(); err != nil {
+			return fmt.Errorf("stopped early, %w; continue with: autocommenter context gen --resume", err)
+		}
+		return fmt.Errorf("context generation failed for %d files; retry them with: autocommenter context gen --resume", failed)
+	}
+	return nil
+}
+
+// generateContextBatch asks the provider for the context of files, retrying transient failures.
+func generateContextBatch(ctx context.Context, provider ai.Provider, files []scanner.Data) ([]contextstore.FileDetails, error) {
+	return providerutil.DoWithRetry[[]contextstore.FileDetails](
+		providerutil.MaxRetryAttempts,
+		providerutil.PerRequestTimeout,
+		func() ([]contextstore.FileDetails, error) {
+			return provider.GenerateContextBatch(ctx, files)
+		},
+	)
+}
+
+// reconcileOutcome totals how the provider's batch output matched the requested files over a run.
+type reconcileOutcome struct {
+	unknown    []string // Returned paths that matched no requested file.
+	duplicates int      // Files returned more than once.
+	retried    int      // Files left out of a batch and requested on their own.
+	recovered  int      // Retried files that came back.
+	empty      []string // Files saved with an empty summary.
+}
+
+// add records the reconciliation of one batch, after retries.
+func (o *reconcileOutcome) add(rootPath string, rec contextstore.Reconciliation, retried int) {
+	o.unknown = append(o.unknown, rec.Unknown...)
+	o.duplicates += len(rec.Duplicates)
+	o.retried += retried
+	o.recovered += retried - min(retried, len(rec.Missing))
+	for _, p := range rec.Empty {
+		o.empty = append(o.empty, relPath(rootPath, p))
+	}
+}
+
+// print reports anything that did not match cleanly, and nothing otherwise.
+func (o *reconcileOutcome) print() {
+	if len(o.unknown) == 0 && o.duplicates == 0 && o.retried == 0 && len(o.empty) == 0 {
+		return
+	}
+	fmt.Printf("provider output: %d unknown entries rejected, %d duplicates ignored, %d missing files retried (%d recovered), %d empty summaries\n",
+		len(o.unknown), o.duplicates, o.retried, o.recovered, len(o.empty))
+	sort.Strings(o.unknown)
+	sort.Strings(o.empty)
+	printDiffSection("rejected paths", slices.Compact(o.unknown))
+	printDiffSection("empty summaries", o.empty)
+}
+
+// estimateContext prints what generating batches would send, including the
+// overview request that follows, without calling the provider.
+func estimateContext(provider ai.Provider, rootPath string, stored *contextstore.Store, batches [][]scanner.Data, contents map[string]scanner.Data) {
+	meter := usage.NewMeter("context gen")
+	model := ai.ModelFor(provider, ai.TaskContext)
+
+	// The overview is built from summaries that do not exist yet, so entries with
+	// a summary of typical length stand in for them.
+	placeholder := strings.Repeat("summary ", 40)
+	files := 0
+	for _, b := range batches {
+		for _, d := range b {
+			if d.Part <= 1 {
+				files++
+				rel := relPath(rootPath, d.Path)
+				stored.Files[rel], _ = withStaticFacts(rootPath, contextstore.FileDetails{Path: rel, Summary: placeholder}, contents)
+			}
+		}
+		meter.Add(batchLabel(rootPath, b), model, ai.EstimateContextBatch(provider, b))
+	}
+
+	modulePath, _ := scanner.ModulePath(rootPath)
+	if overview := stored.RefreshPackages(modulePath); len(overview.Packages) > 0 {
+		if u, err := ai.EstimateOverview(provider, overview); err == nil {
+			meter.Add("overview", ai.ModelFor(provider, ai.TaskOverview), u)
+		}
+	}
+
+	fmt.Printf("%d files to generate in %d batches\n", files, len(batches))
+	printEstimate(meter)
+}
+
+// batchLabel names a batch by its first file and how many others it holds.
+func batchLabel(rootPath string, b []scanner.Data) string {
+	label := relPath(rootPath, b[0].Path)
+	if b[0].Parts > 1 {
+		label = scanner.Data{Path: label, Part: b[0].Part, Parts: b[0].Parts}.Label()
+	}
+	if len(b) > 1 {
+		label += fmt.Sprintf(" +%d more", len(b)-1)
+	}
+	return label
+}
+
+// resolveBatchLimits picks each batch limit from its flag, then project config, then the default.
+func resolveBatchLimits(cfg *config.ProjectConfig) scanner.BatchLimits {
+	limits := scanner.DefaultBatchLimits
+	switch {
+	case batchInput > 0:
+		limits.MaxInputTokens = batchInput
+	case cfg.Context.Batch.MaxInputTokens > 0:
+		limits.MaxInputTokens = cfg.Context.Batch.MaxInputTokens
+	}
+	switch {
+	case batchOutput > 0:
+		limits.MaxOutputTokens = batchOutput
+	case cfg.Context.Batch.MaxOutputTokens > 0:
+		limits.MaxOutputTokens = cfg.Context.Batch.MaxOutputTokens
+	}
+	return limits
+}
+
+// saveCheckpoint writes the run checkpoint, reporting but not failing on errors:
+// losing it only means a resumed run redoes more work.
+func saveCheckpoint(c *contextstore.Checkpoint) {
+	if err := contextstore.SaveCheckpoint(c); err != nil {
+		fmt.Println("checkpoint save error:", err)
+	}
+}
+
+// printFailures lists failed files with their reasons, grouping files that failed for the same reason.
+func printFailures(failed map[string]string) {
+	byReason := map[string][]string{}
+	for path, reason := range failed {
+		byReason[reason] = append(byReason[reason], path)
+	}
+	reasons := make([]string, 0, len(byReason))
+	for r := range byReason {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+
+	fmt.Println("\nFailed files:")
+	for _, r := range reasons {
+		paths := byReason[r]
+		sort.Strings(paths)
+		fmt.Printf("  %s\n", r)
+		for _, p := range paths {
+			fmt.Printf("    %s\n", p)
+		}
+	}
+}
+
+// refreshOverview asks the provider for the package summaries and module overview
+// that pending lacks and merges them into the saved context. Failures are reported
+// but do not fail the run, since file context is still useful on its own.
+func refreshOverview(ctx context.Context, provider ai.Provider, pending contextstore.Hierarchy, modulePath string) {
+	progressBar := progress.New("overview", 1)
+	task := progressBar.Start(fmt.Sprintf("overview of %d packages", len(pending.Packages)))
+	ctx = progress.WithTask(ctx, task)
+	type overview struct {
+		packages []contextstore.PackageDetails
+		module   contextstore.ModuleDetails
+	}
+	result, err := providerutil.DoWithRetry[overview](
+		providerutil.MaxRetryAttempts,
+		providerutil.PerRequestTimeout,
+		func() (overview, error) {
+			packages, module, err := provider.GenerateOverview(ctx, pending)
+			return overview{packages, module}, err
+		},
+	)
+	task.Done(err)
+	progressBar.Stop()
+	if err != nil {
+		return
+	}
+
+	// Tag each summary with the hash it was generated for, so summaries of packages
+	// another run changed in the meantime are not applied.
+	hashes := make(map[string]string, len(pending.Packages))
+	for _, p := range pending.Packages {
+		hashes[p.Path] = p.Hash
+	}
+	for i := range result.packages {
+		result.packages[i].Hash = hashes[result.packages[i].Path]
+	}
+	result.module.Hash = pending.Module.Hash
+
+	err = contextstore.Update(func(latest *contextstore.Store) error {
+		latest.RefreshPackages(modulePath)
+		latest.ApplyOverview(result.packages, result.module)
 		return nil
-	},
+	})
+	if err != nil {
+		fmt.Println("overview save error:", err)
+	}
 }
 
-func init() {
-	contextGenCmd.SilenceUsage = true
-	// contextGenCmd.SilenceErrors = true
+// buildImportGraph parses the imports of every scanned Go file.
+func buildImportGraph(rootPath string, files []scanner.Info) contextstore.Graph {
+	var goFiles []scanner.Info
+	for _, f := range files {
+		if filepath.Ext(f.Path) == ".go" {
+			goFiles = append(goFiles, f)
+		}
+	}
 
-	rootCmd.AddCommand(contextCmd)
-	contextCmd.AddCommand(contextGenCmd)
+	modulePath, _ := scanner.ModulePath(rootPath) // Without it, packages are named by directory.
+	return contextstore.BuildGraph(rootPath, modulePath, scanner.Load(goFiles))
+}
+
+// withStaticFacts replaces model-reported facts of a Go file with those extracted
+// from its source, keeping the model's summary. It returns the entry and a line
+// for each disagreement, for the caller to print.
+func withStaticFacts(rootPath string, item contextstore.FileDetails, contents map[string]scanner.Data) (contextstore.FileDetails, []string) {
+	data, ok := contents[filepath.Join(rootPath, filepath.FromSlash(item.Path))]
+	if !ok {
+		return item, nil
+	}
+	if item.Name == "" {
+		item.Name = filepath.Base(item.Path)
+	}
+
+	static, err := contextstore.StaticDetails(item.Path, data.Content)
+	if err != nil {
+		return item, nil // Not Go, or unparsable: keep what the model reported.
+	}
+
+	merged, warnings := contextstore.ApplyStatic(item, static, data.Content)
+	lines := make([]string, len(warnings))
+	for i, w := range warnings {
+		lines[i] = fmt.Sprintf("  warning %s: %s", item.Path, w)
+	}
+	return merged, lines
+}
+
+// relPath returns path relative to rootPath with forward slashes, as used for context keys.
+func relPath(rootPath, path string) string {
+	rel, err := filepath.Rel(rootPath, path)
+	if err != nil || rel == "." { // Handle errors or if the file is at the root
+		return filepath.Clean(path)
+	}
+	return filepath.ToSlash(rel) // Use forward slashes for consistent paths
 }
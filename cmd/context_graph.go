@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat  string // Output format for context graph
+	graphStdlib  bool   // Include standard library imports
+	graphRebuild bool   // Rebuild the graph from source instead of using the stored one
+)
+
+var contextGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the package import graph",
+	Long: `Print which packages of the module import which packages.
+The graph is stored with the project context by 'context gen'; it is
+built from source when no stored graph exists or --rebuild is passed.
+Standard library imports are hidden unless --stdlib is set.
+
+Examples:
+  autocommenter context graph
+  autocommenter context graph --format dot | dot -Tsvg > imports.svg
+  autocommenter context graph --format mermaid --stdlib
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := scanner.GetProjectRoot()
+
+		store, loadErr := contextstore.Load()
+		var graph contextstore.Graph
+		if loadErr == nil && store.Graph != nil && !graphRebuild {
+			graph = *store.Graph
+		} else {
+			files, err := scanner.Scan(rootPath)
+			if err != nil {
+				return fmt.Errorf("scan failed: %w", err)
+			}
+			graph = buildImportGraph(rootPath, files)
+
+			// Keep the fresh graph when there is a context file to keep it in.
+			if loadErr == nil {
+				err := contextstore.Update(func(latest *contextstore.Store) error {
+					latest.Graph = &graph
+					return nil
+				})
+				if err != nil {
+					return fmt.Errorf("context save failed: %w", err)
+				}
+			}
+		}
+
+		kinds := []string{contextstore.ImportInternal, contextstore.ImportExternal}
+		if graphStdlib {
+			kinds = append(kinds, contextstore.ImportStdlib)
+		}
+		graph = graph.Filter(kinds...)
+
+		switch graphFormat {
+		case "text":
+			graph.WriteText(os.Stdout)
+		case "json":
+			return printJSON(graph)
+		case "dot":
+			graph.WriteDOT(os.Stdout)
+		case "mermaid":
+			graph.WriteMermaid(os.Stdout)
+		default:
+			return fmt.Errorf("unknown format %q: supported formats are text, json, dot, mermaid", graphFormat)
+		}
+		return nil
+	},
+}
+
+func init() {
+	contextGraphCmd.SilenceUsage = true
+
+	contextGraphCmd.Flags().StringVarP(&graphFormat, "format", "f", "text", "Output format: text, json, dot or mermaid")
+	contextGraphCmd.Flags().BoolVar(&graphStdlib, "stdlib", false, "Include standard library imports")
+	contextGraphCmd.Flags().BoolVar(&graphRebuild, "rebuild", false, "Rebuild the graph from source")
+
+	contextCmd.AddCommand(contextGraphCmd)
+}
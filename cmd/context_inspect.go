@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var inspectJSON bool // Print inspection results as JSON
+
+// listSummaryWidth is how much of each summary 'context list' shows.
+const listSummaryWidth = 60
+
+var contextListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored file context",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := contextstore.Load()
+		if err != nil {
+			return fmt.Errorf("context load failed: %w", err)
+		}
+		files := store.Hierarchy().Files
+
+		if inspectJSON {
+			return printJSON(files)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PATH\tEXPORTS\tAGE\tSUMMARY")
+		for _, f := range files {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", f.Path, len(f.Exports), age(f.GeneratedAt), truncate(f.Summary, listSummaryWidth))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d files\n", len(files))
+		return nil
+	},
+}
+
+var contextShowCmd = &cobra.Command{
+	Use:   "show <path>",
+	Short: "Show the stored context of one file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := contextstore.Load()
+		if err != nil {
+			return fmt.Errorf("context load failed: %w", err)
+		}
+
+		key := contextKey(store, args[0])
+		f, ok := store.Files[key]
+		if !ok {
+			return fmt.Errorf("no context stored for %s", key)
+		}
+
+		if inspectJSON {
+			return printJSON(f)
+		}
+
+		fmt.Println("Path:     ", f.Path)
+		if f.Package != "" {
+			fmt.Println("Package:  ", f.Package)
+		}
+		if f.BuildTags != "" {
+			fmt.Println("Build:    ", f.BuildTags)
+		}
+		fmt.Println("Generated:", formatTime(f.GeneratedAt), "("+age(f.GeneratedAt)+" ago)")
+		if f.Model != "" {
+			fmt.Println("Model:    ", f.Model)
+		}
+		fmt.Println("Hash:     ", f.Hash)
+		fmt.Printf("\nSummary:\n  %s\n", f.Summary)
+		if len(f.Exports) > 0 {
+			fmt.Println("\nExports:")
+			for _, e := range f.Exports {
+				if e.Signature != "" {
+					fmt.Println(" ", e.Signature)
+				} else {
+					fmt.Println(" ", e.Name)
+				}
+			}
+		}
+		if len(f.Imports) > 0 {
+			fmt.Println("\nImports:")
+			for _, imp := range f.Imports {
+				fmt.Println(" ", imp)
+			}
+		}
+		return nil
+	},
+}
+
+var contextDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare stored context with the current files",
+	Long: `List files without context (missing), files changed since their
+context was generated (stale) and entries for deleted files (orphaned).
+Exits with an error when the context is out of date.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, diff, err := loadContextDiff()
+		if err != nil {
+			return err
+		}
+
+		if inspectJSON {
+			if err := printJSON(diff); err != nil {
+				return err
+			}
+		} else {
+			printDiffSection("missing", diff.Missing)
+			printDiffSection("stale", diff.Stale)
+			printDiffSection("orphaned", diff.Orphaned)
+			fmt.Printf("%d stored, %d missing, %d stale, %d orphaned\n",
+				len(store.Files), len(diff.Missing), len(diff.Stale), len(diff.Orphaned))
+		}
+
+		if n := len(diff.Missing) + len(diff.Stale) + len(diff.Orphaned); n > 0 {
+			return fmt.Errorf("context is out of date for %d files; run: autocommenter context gen", n)
+		}
+		return nil
+	},
+}
+
+var contextPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove context entries for files that no longer exist",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, diff, err := loadContextDiff()
+		if err != nil {
+			return err
+		}
+		return removeEntries(diff.Orphaned)
+	},
+}
+
+var contextRmCmd = &cobra.Command{
+	Use:   "rm <path>...",
+	Short: "Remove the stored context of the given files",
+	Long: `Remove stored context entries so the next 'context gen' regenerates them.
+
+Example:
+  autocommenter context rm internal/scanner/scan.go
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := contextstore.Load()
+		if err != nil {
+			return fmt.Errorf("context load failed: %w", err)
+		}
+
+		keys := make([]string, 0, len(args))
+		for _, arg := range args {
+			key := contextKey(store, arg)
+			if _, ok := store.Files[key]; !ok {
+				return fmt.Errorf("no context stored for %s", key)
+			}
+			keys = append(keys, key)
+		}
+		return removeEntries(keys)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{contextListCmd, contextShowCmd, contextDiffCmd, contextPruneCmd, contextRmCmd} {
+		c.SilenceUsage = true
+		c.Flags().BoolVar(&inspectJSON, "json", false, "Print output as JSON")
+		contextCmd.AddCommand(c)
+	}
+}
+
+// loadContextDiff loads the store and compares it with the files currently in the project.
+func loadContextDiff() (*contextstore.Store, contextstore.Diff, error) {
+	store, err := contextstore.Load()
+	if err != nil {
+		return nil, contextstore.Diff{}, fmt.Errorf("context load failed: %w", err)
+	}
+
+	rootPath := scanner.GetProjectRoot()
+	files, err := scanner.Scan(rootPath)
+	if err != nil {
+		return nil, contextstore.Diff{}, fmt.Errorf("scan failed: %w", err)
+	}
+
+	current := make(map[string]string, len(files))
+	for _, data := range scanner.Load(files) {
+		current[relPath(rootPath, data.Path)] = contextstore.HashContent(data.Content)
+	}
+	return store, store.Diff(current), nil
+}
+
+// removeEntries deletes the given entries from the saved context, regroups packages and saves it.
+func removeEntries(keys []string) error {
+	var removed []string
+	err := contextstore.Update(func(latest *contextstore.Store) error {
+		removed = latest.Remove(keys...)
+		if len(removed) == 0 {
+			return contextstore.ErrSkipSave
+		}
+		modulePath, _ := scanner.ModulePath(scanner.GetProjectRoot())
+		latest.RefreshPackages(modulePath) // Changed packages are summarized again by the next 'context gen'.
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("context save failed: %w", err)
+	}
+
+	if inspectJSON {
+		if removed == nil {
+			removed = []string{}
+		}
+		return printJSON(map[string][]string{"removed": removed})
+	}
+	for _, p := range removed {
+		fmt.Println("removed", p)
+	}
+	fmt.Printf("%d entries removed\n", len(removed))
+	return nil
+}
+
+// contextKey turns a path argument into a store key. Paths are tried relative to
+// the working directory first, then as given relative to the project root.
+func contextKey(store *contextstore.Store, arg string) string {
+	if abs, err := filepath.Abs(arg); err == nil {
+		key := relPath(scanner.GetProjectRoot(), abs)
+		if _, ok := store.Files[key]; ok {
+			return key
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(arg))
+}
+
+// printDiffSection prints a heading and one line per path, or nothing when paths is empty.
+func printDiffSection(label string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d):\n", label, len(paths))
+	for _, p := range paths {
+		fmt.Println(" ", p)
+	}
+	fmt.Println()
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// age renders the time since t compactly, e.g. "45m", "6h" or "12d".
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// formatTime renders t in local time, or "unknown" for entries without a timestamp.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// truncate shortens s to at most n runes on a single line.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOut    string // File to write the snapshot to; stdout when empty
+	exportFormat string // Snapshot format for export
+	importFormat string // Snapshot format for import
+)
+
+var contextExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the stored context to a portable snapshot",
+	Long: `Write the stored context, with the module path and tool version, to a
+snapshot that 'context import' can load on another machine. The format is
+taken from --format or from the --out extension (.json, .jsonl, .toon).
+
+Examples:
+  autocommenter context export --out context.json
+  autocommenter context export --out context.toon
+  autocommenter context export --format jsonl > context.jsonl
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := contextstore.Load()
+		if err != nil {
+			return fmt.Errorf("context load failed: %w", err)
+		}
+		modulePath, err := scanner.ModulePath(scanner.GetProjectRoot())
+		if err != nil {
+			return fmt.Errorf("module path: %w", err)
+		}
+
+		format := exportFormat
+		if format == "" {
+			format = contextstore.FormatFromPath(exportOut)
+		}
+
+		var buf bytes.Buffer
+		snapshot := contextstore.NewSnapshot(store, modulePath, version)
+		if err := snapshot.Encode(&buf, format); err != nil {
+			return err
+		}
+
+		if exportOut == "" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		if err := os.WriteFile(exportOut, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("write snapshot: %w", err)
+		}
+		fmt.Printf("Exported %d files and %d packages to %s (%s)\n", len(store.Files), len(store.Packages), exportOut, format)
+		return nil
+	},
+}
+
+var contextImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Replace the stored context with a snapshot",
+	Long: `Load a snapshot written by 'context export' and store it as the project
+context. Snapshots from another module or a newer schema are rejected.
+Entries for files that changed since the export are regenerated by the
+next 'context gen'.
+
+Example:
+  autocommenter context import context.json
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("read snapshot: %w", err)
+		}
+
+		format := importFormat
+		if format == "" {
+			format = sniffSnapshotFormat(args[0], data)
+		}
+		snapshot, err := contextstore.DecodeSnapshot(data, format)
+		if err != nil {
+			return err
+		}
+
+		modulePath, err := scanner.ModulePath(scanner.GetProjectRoot())
+		if err != nil {
+			return fmt.Errorf("module path: %w", err)
+		}
+		if err := snapshot.Validate(modulePath); err != nil {
+			return fmt.Errorf("cannot import %s: %w", args[0], err)
+		}
+
+		if err := contextstore.Save(snapshot.Context); err != nil {
+			return fmt.Errorf("context save failed: %w", err)
+		}
+		fmt.Printf("Imported %d files and %d packages (written by autocommenter %s on %s)\n",
+			len(snapshot.Context.Files), len(snapshot.Context.Packages), snapshot.Meta.ToolVersion, formatTime(snapshot.Meta.CreatedAt))
+
+		if _, diff, err := loadContextDiff(); err == nil {
+			if n := len(diff.Missing) + len(diff.Stale) + len(diff.Orphaned); n > 0 {
+				fmt.Printf("%d files differ from the snapshot; run: autocommenter context gen\n", n)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	contextExportCmd.SilenceUsage = true
+	contextImportCmd.SilenceUsage = true
+
+	contextExportCmd.Flags().StringVarP(&exportOut, "out", "o", "", "File to write the snapshot to (default stdout)")
+	contextExportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "Snapshot format: json, jsonl or toon (default from --out extension)")
+	contextImportCmd.Flags().StringVarP(&importFormat, "format", "f", "", "Snapshot format: json, jsonl or toon (default detected)")
+
+	contextCmd.AddCommand(contextExportCmd)
+	contextCmd.AddCommand(contextImportCmd)
+}
+
+// sniffSnapshotFormat picks the format from a snapshot extension, falling back to
+// the content otherwise: a first line holding a JSONL meta record means JSONL, any
+// other leading brace means JSON, and anything else TOON.
+func sniffSnapshotFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl", ".ndjson", ".toon":
+		return contextstore.FormatFromPath(path)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return contextstore.FormatTOON
+	}
+	firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	var record struct {
+		Kind string `json:"kind"`
+	}
+	if json.Unmarshal(firstLine, &record) == nil && record.Kind == "meta" {
+		return contextstore.FormatJSONL
+	}
+	return contextstore.FormatJSON
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/praneeth-ayla/autocommenter/internal/ai"
+	"github.com/praneeth-ayla/autocommenter/internal/config"
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var estimateOnly bool // Flag to print a cost estimate instead of calling the provider
+
+// addEstimateFlag registers --estimate on a generation command.
+func addEstimateFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&estimateOnly, "estimate", false, "Build the prompts and print estimated requests, tokens and cost without calling the provider")
+}
+
+// loadProvider returns the configured provider. Estimates never send a request,
+// so they do not need the provider's credentials.
+func loadProvider() (ai.Provider, error) {
+	providerName, _ := config.GetProvider()
+	if estimateOnly {
+		return ai.NewOffline(providerName)
+	}
+	return ai.NewProvider(providerName)
+}
+
+// loadContext loads the stored context, without migrating or upgrading the
+// context file when only estimating.
+func loadContext() (*contextstore.Store, error) {
+	if estimateOnly {
+		return contextstore.LoadReadOnly()
+	}
+	return contextstore.Load()
+}
+
+// printEstimate prints the estimated requests, tokens and cost per model, followed by notes on what the estimate leaves out.
+func printEstimate(meter *usage.Meter, notes ...string) {
+	run := meter.Run("", config.Prices())
+	fmt.Println("\nEstimate (no requests were sent):")
+	rows, _ := usage.Group([]usage.Run{run}, "model")
+	for _, r := range rows {
+		cost := usage.FormatCost(r.Cost)
+		if _, ok := config.Prices()[r.Key]; !ok {
+			cost = "no price"
+		}
+		fmt.Printf("  %-24s %4d requests  %8s input  %8s output  %s\n", r.Key, r.Usage.Requests,
+			usage.FormatTokens(r.Usage.Prompt), usage.FormatTokens(r.Usage.Output), cost)
+	}
+	fmt.Printf("Total: %d requests, %s input and %s output tokens, about %s\n", run.Usage.Requests,
+		usage.FormatTokens(run.Usage.Prompt), usage.FormatTokens(run.Usage.Output), usage.FormatCost(run.Cost))
+
+	notes = append(notes, "thinking tokens are not included, and outputs are rough guesses")
+	for _, n := range notes {
+		fmt.Println("  note:", n)
+	}
+}
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/praneeth-ayla/autocommenter/internal/ai"
+	"github.com/praneeth-ayla/autocommenter/internal/config"
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/git"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
+	"github.com/praneeth-ayla/autocommenter/internal/prompt"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies a pre-commit hook written by autocommenter.
+const hookMarker = "# autocommenter pre-commit hook"
+
+// prevHookName is where an existing pre-commit hook is kept while ours is installed.
+const prevHookName = "pre-commit.autocommenter-prev"
+
+// hookScript checks staged files after running any hook that was installed before it.
+const hookScript = `#!/bin/sh
+` + hookMarker + `
+# Remove with: autocommenter hook uninstall
+prev="$(dirname "$0")/` + prevHookName + `"
+if [ -x "$prev" ]; then
+	"$prev" "$@" || exit $?
+fi
+exec autocommenter hook run
+`
+
+// hookCmd represents the hook command
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git pre-commit hook",
+	Long: `Install or remove a git pre-commit hook that checks staged Go files
+for exported declarations without doc comments.
+
+Set "hook" in .autocommenter.json to generate comments for those files
+and re-stage them, or to block the commit while docs are missing.
+
+Example:
+  autocommenter hook install
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Use 'autocommenter hook install' to install the pre-commit hook")
+	},
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit hook, chaining any existing hook",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hookPath, prevPath, err := hookPaths()
+		if err != nil {
+			return err
+		}
+
+		existing, err := os.ReadFile(hookPath)
+		switch {
+		case os.IsNotExist(err):
+			// Nothing to preserve.
+		case err != nil:
+			return err
+		case bytes.Contains(existing, []byte(hookMarker)):
+			// Already ours; rewrite it so upgrades pick up script changes.
+		default:
+			if _, err := os.Stat(prevPath); err == nil {
+				return fmt.Errorf("%s already exists; refusing to overwrite a preserved hook", prevPath)
+			}
+			if err := os.Rename(hookPath, prevPath); err != nil {
+				return fmt.Errorf("could not preserve existing hook: %w", err)
+			}
+			fmt.Println("Existing pre-commit hook kept as", prevHookName, "and chained")
+		}
+
+		if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(hookPath, []byte(hookScript), 0755); err != nil {
+			return fmt.Errorf("failed to write hook: %w", err)
+		}
+
+		fmt.Println("Pre-commit hook installed:", hookPath)
+		return nil
+	},
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the pre-commit hook and restore the previous one",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hookPath, prevPath, err := hookPaths()
+		if err != nil {
+			return err
+		}
+
+		existing, err := os.ReadFile(hookPath)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no pre-commit hook installed")
+		}
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(existing, []byte(hookMarker)) {
+			return fmt.Errorf("pre-commit hook was not installed by autocommenter; leaving it alone")
+		}
+
+		if err := os.Remove(hookPath); err != nil {
+			return err
+		}
+		if _, err := os.Stat(prevPath); err == nil {
+			if err := os.Rename(prevPath, hookPath); err != nil {
+				return fmt.Errorf("could not restore previous hook: %w", err)
+			}
+			fmt.Println("Previous pre-commit hook restored")
+		}
+
+		fmt.Println("Pre-commit hook removed")
+		return nil
+	},
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:    "run",
+	Short:  "Run the pre-commit check on staged files",
+	Hidden: true, // Invoked by the installed hook script.
+	RunE:   runHook,
+}
+
+func init() {
+	hookInstallCmd.SilenceUsage = true
+	hookUninstallCmd.SilenceUsage = true
+	hookRunCmd.SilenceUsage = true
+
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookCmd.AddCommand(hookRunCmd)
+}
+
+// hookPaths returns the pre-commit hook path and the path an existing hook is preserved at.
+func hookPaths() (string, string, error) {
+	dir, err := git.HooksDir(scanner.GetProjectRoot())
+	if err != nil {
+		return "", "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return filepath.Join(dir, "pre-commit"), filepath.Join(dir, prevHookName), nil
+}
+
+func runHook(cmd *cobra.Command, args []string) error {
+	rootPath := scanner.GetProjectRoot()
+	projectCfg, err := config.LoadProject(rootPath)
+	if err != nil {
+		return fmt.Errorf("project config: %w", err)
+	}
+
+	files, err := scanner.Scan(rootPath)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+
+	scope := gitScope{opts: git.DiffOptions{Staged: true}}
+	files, err = scope.apply(rootPath, files)
+	if err != nil {
+		return err
+	}
+	files = scanner.FilterFilesNeedingComments(rootPath, files, filterRules(projectCfg))
+	pol, err := policy.Load(rootPath)
+	if err != nil {
+		return fmt.Errorf("egress policy: %w", err)
+	}
+
+	reports, _ := findMissingDocs(rootPath, files, &scope)
+	if len(reports) == 0 {
+		return nil
+	}
+
+	hookCfg := projectCfg.Hook
+	if !hookCfg.Generate {
+		missing := printMissingDocs(reports)
+		if hookCfg.Strict {
+			return fmt.Errorf("%d exported declarations missing doc comments; run: autocommenter comments gen --staged", missing)
+		}
+		fmt.Println("autocommenter: commit continues; set hook.strict to block it")
+		return nil
+	}
+
+	style := hookCfg.Style
+	if style == "" {
+		style = defaultCommentStyle
+	}
+	if !slices.Contains(prompt.Styles, style) {
+		return fmt.Errorf("unknown hook style %q", style)
+	}
+
+	providerName, _ := config.GetProvider()
+	provider, err := ai.NewProvider(providerName)
+	if err != nil {
+		return fmt.Errorf("provider init: %w", err)
+	}
+	if provider, err = withPolicy(provider, pol, rootPath, commandName(cmd)); err != nil {
+		return err
+	}
+
+	// Context improves results but is not required for a quick hook run.
+	var hierarchy contextstore.Hierarchy
+	if store, err := contextstore.Load(); err == nil {
+		hierarchy = store.Hierarchy()
+	}
+
+	meter := usage.NewMeter(commandName(cmd))
+	defer finishUsage(meter, rootPath)
+	provider, redactor := withRedaction(provider)
+	defer printRedactions(redactor, rootPath)
+	provider, _ = withBudget(provider, projectCfg, meter) // Limits from .autocommenter.json apply to the hook too.
+	runCtx := runContext(meter)
+
+	// Re-staging a file with unstaged changes would commit them too.
+	rels := make([]string, len(reports))
+	for i, r := range reports {
+		rels[i] = r.Rel
+	}
+	unstaged, err := git.UnstagedPaths(rootPath, rels...)
+	if err != nil {
+		return fmt.Errorf("git diff failed: %w", err)
+	}
+
+	failed := 0
+	for _, r := range reports {
+		if slices.Contains(unstaged, r.Rel) {
+			fmt.Printf("autocommenter: skipped %s: it has unstaged changes; stage or stash them to document it\n", r.Rel)
+			failed++
+			continue
+		}
+		fmt.Println("autocommenter: documenting", r.Rel)
+		ctx := usage.WithFile(runCtx, r.Rel)
+		err := processFile(ctx, r.File, provider, hierarchy, style, resolveContextBudget(projectCfg))
+		if errors.Is(err, policy.ErrWithheld) {
+			fmt.Printf("  skipped: %v\n", err) // Not a failure; the file may not be sent anywhere.
+			continue
+		}
+		if err != nil {
+			fmt.Printf("  ✖ error: %v\n", err)
+			failed++
+			continue
+		}
+		if err := git.Add(rootPath, r.Rel); err != nil {
+			return fmt.Errorf("re-stage %s: %w", r.Rel, err)
+		}
+	}
+
+	if failed > 0 && hookCfg.Strict {
+		return fmt.Errorf("comment generation failed for %d files", failed)
+	}
+	return nil
+}
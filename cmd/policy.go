@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/praneeth-ayla/autocommenter/internal/ai"
+	"github.com/praneeth-ayla/autocommenter/internal/config"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+)
+
+// loadPolicy reads the project's egress policy and returns it with the gate for provider.
+func loadPolicy(rootPath string, provider ai.Provider) (*policy.Policy, *policy.Gate, error) {
+	pol, err := policy.Load(rootPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("egress policy: %w", err)
+	}
+	return pol, pol.Gate(ai.IsLocal(provider)), nil
+}
+
+// withPolicy wraps provider so every request is checked against the egress
+// policy and recorded in the audit log. It must be the first wrapper applied.
+func withPolicy(provider ai.Provider, pol *policy.Policy, rootPath, command string) (ai.Provider, error) {
+	path := pol.AuditPath()
+	if path == "" {
+		var err error
+		if path, err = config.AuditLogPath(); err != nil {
+			return nil, err
+		}
+	}
+	providerName, _ := config.GetProvider()
+	return ai.WithPolicy(provider, pol, policy.OpenAudit(path, rootPath, command, providerName)), nil
+}
+
+// withholdFiles drops the files gate withholds and lists them.
+func withholdFiles(rootPath string, files []scanner.Info, gate *policy.Gate) []scanner.Info {
+	var kept []scanner.Info
+	var withheld []string
+	for _, f := range files {
+		if gate.Allows(f.Path) {
+			kept = append(kept, f)
+		} else {
+			withheld = append(withheld, relPath(rootPath, f.Path))
+		}
+	}
+	if len(withheld) > 0 {
+		printDiffSection(fmt.Sprintf("withheld from the %s by %s", gate, policy.FileName), withheld)
+	}
+	return kept
+}
@@ -9,9 +9,13 @@ import (
 	"path/filepath"
 
 	"github.com/praneeth-ayla/autocommenter/internal/ai"
+	"github.com/praneeth-ayla/autocommenter/internal/ai/providerutil"
 	"github.com/praneeth-ayla/autocommenter/internal/config"
 	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
+	"github.com/praneeth-ayla/autocommenter/internal/progress"
 	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
 	"github.com/spf13/cobra"
 )
 
@@ -31,7 +35,8 @@ Example:
 }
 
 var (
-	readmePath string // Flag for custom README path
+	readmePath   string        // Flag for custom README path
+	readmeCommit commitOptions // Branch and commit handling for readme gen
 )
 
 var genReadmeCmd = &cobra.Command{
@@ -49,23 +54,34 @@ Examples:
   autocommenter readme gen
   autocommenter readme gen --path docs/README.md
   autocommenter readme gen -p ./documentation/README.md
+  autocommenter readme gen --commit --branch docs/readme
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		providerName, _ := config.GetProvider()
-		provider, err := ai.NewProvider(providerName)
+		provider, err := loadProvider()
 		if err != nil {
 			fmt.Println("provider error:", err)
 			return err
 		}
 
 		rootPath := scanner.GetProjectRoot()
+		projectCfg, err := config.LoadProject(rootPath)
+		if err != nil {
+			return fmt.Errorf("project config: %w", err)
+		}
+		pol, gate, err := loadPolicy(rootPath, provider)
+		if err != nil {
+			return err
+		}
+		if provider, err = withPolicy(provider, pol, rootPath, commandName(cmd)); err != nil {
+			return err
+		}
 		fmt.Println("Loading project context...")
-		contextData, err := contextstore.Load()
+		contextData, err := loadContext()
 		if err != nil {
 			return fmt.Errorf("no project context found. Run: autocommenter context gen")
 		}
 
-		allCtxSlice := contextstore.MapToSlice(contextData)
+		hierarchy := contextData.Hierarchy()
 
 		// Determine output path
 		outputPath := filepath.Join(rootPath, "README.md")
@@ -84,7 +100,7 @@ Examples:
 		}
 
 		// check existing README file (check both default location and custom path)
-		var existingReadme string
+		var existingReadme, existingPath string
 		readmePaths := []string{
 			outputPath, // Check the target path first
 			filepath.Join(rootPath, "README.md"),
@@ -94,14 +110,33 @@ Examples:
 		// get custom path from user using --path or -p flag
 		for _, path := range readmePaths {
 			if data, err := os.ReadFile(path); err == nil {
-				existingReadme = string(data)
+				existingReadme, existingPath = string(data), path
 				fmt.Println("Existing README found:", path)
 				break
 			}
 		}
 
-		fmt.Println("️Generating README...")
-		newReadme, err := provider.GenerateReadme(allCtxSlice, existingReadme)
+		if estimateOnly {
+			return estimateReadme(provider, gate, rootPath, hierarchy, existingReadme)
+		}
+
+		if err := readmeCommit.prepare(rootPath, "readme"); err != nil {
+			return err
+		}
+
+		meter := usage.NewMeter(commandName(cmd))
+		provider, redactor := withRedaction(provider)
+		provider, _ = withBudget(provider, projectCfg, meter)
+		rel := relPath(rootPath, outputPath)
+		progressBar := progress.New("readme", 1)
+		task := progressBar.Start(rel)
+		ctx := usage.WithFile(progress.WithTask(runContext(meter), task), rel)
+		ctx = policy.WithSource(ctx, existingPath)
+		newReadme, err := provider.GenerateReadme(ctx, hierarchy, existingReadme)
+		task.Done(err)
+		progressBar.Stop()
+		printRedactions(redactor, rootPath)
+		finishUsage(meter, rootPath)
 		if err != nil {
 			return fmt.Errorf("README generation failed: %w", err)
 		}
@@ -112,7 +147,7 @@ Examples:
 		}
 
 		fmt.Println(";) README.md updated:", outputPath)
-		return nil
+		return readmeCommit.finish(rootPath, "docs: update generated README")
 	},
 }
 
@@ -122,7 +157,28 @@ func init() {
 
 	// Add path flag
 	genReadmeCmd.Flags().StringVarP(&readmePath, "path", "p", "", "Custom path for README file (default: ./README.md)")
+	addCommitFlags(genReadmeCmd, &readmeCommit)
+	addEstimateFlag(genReadmeCmd)
+	addBudgetFlags(genReadmeCmd)
+	addCacheFlag(genReadmeCmd)
 
 	rootCmd.AddCommand(readmeCmd)
 	readmeCmd.AddCommand(genReadmeCmd)
 }
+
+// estimateReadme prints what generating the README would send without calling the provider.
+func estimateReadme(provider ai.Provider, gate *policy.Gate, rootPath string, hierarchy contextstore.Hierarchy, existingReadme string) error {
+	tree, err := providerutil.BuildFileTree(rootPath, gate.Allows)
+	if err != nil {
+		return err
+	}
+	u, err := ai.EstimateReadme(provider, hierarchy, existingReadme, tree)
+	if err != nil {
+		return err
+	}
+
+	meter := usage.NewMeter("readme gen")
+	meter.Add("README.md", ai.ModelFor(provider, ai.TaskReadme), u)
+	printEstimate(meter)
+	return nil
+}
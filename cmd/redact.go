@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/praneeth-ayla/autocommenter/internal/ai"
+	"github.com/praneeth-ayla/autocommenter/internal/redact"
+)
+
+// withRedaction wraps provider so that secrets detected in source are replaced
+// by placeholders before each request and restored in what it returns.
+func withRedaction(provider ai.Provider) (ai.Provider, *redact.Redactor) {
+	r := redact.New()
+	return ai.WithRedaction(provider, r), r
+}
+
+// printRedactions warns about every secret that was kept out of a request.
+func printRedactions(r *redact.Redactor, rootPath string) {
+	findings := r.Findings()
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Printf("\nwarning: %d possible secrets were redacted before sending; review and move them out of source:\n", len(findings))
+	for _, f := range findings {
+		if f.Line == 0 {
+			fmt.Printf("  %s: %s in the stored context\n", relPath(rootPath, f.Source), f.Kind)
+			continue
+		}
+		fmt.Printf("  %s:%d: %s\n", relPath(rootPath, f.Source), f.Line, f.Kind)
+	}
+}
@@ -9,11 +9,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// version is the autocommenter release, set at build time with
+// -ldflags "-X github.com/praneeth-ayla/autocommenter/cmd.version=v1.2.3".
+var version = "dev"
+
 // rootCmd represents the base command when called without any subcommands.
 // Defines the primary command and its metadata like usage, short description, and long description.
 var rootCmd = &cobra.Command{
-	Use:   "autocommenter",
-	Short: "A brief description of your application",
+	Use:     "autocommenter",
+	Version: version,
+	Short:   "A brief description of your application",
 	Long: `A longer description that spans multiple lines and likely contains
 examples and usage of using your application. For example:
 
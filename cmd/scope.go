@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/praneeth-ayla/autocommenter/internal/git"
+	"github.com/praneeth-ayla/autocommenter/internal/gosrc"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// gitScope restricts a command to files changed in git.
+type gitScope struct {
+	opts  git.DiffOptions
+	hunks bool // Only consider declarations touched by the diff hunks.
+
+	changes map[string][]git.LineRange // Changed lines keyed by relative path, set by apply.
+}
+
+// addGitScopeFlags registers --since and --staged, plus --hunks when withHunks is set.
+func addGitScopeFlags(c *cobra.Command, s *gitScope, withHunks bool) {
+	c.Flags().StringVar(&s.opts.Since, "since", "", "Only process files changed since this git ref (merge base with HEAD)")
+	c.Flags().BoolVar(&s.opts.Staged, "staged", false, "Only process files with staged changes")
+	if withHunks {
+		c.Flags().BoolVar(&s.hunks, "hunks", false, "With --since or --staged, only check Go declarations touched by the diff")
+	}
+}
+
+// apply narrows files to those changed in git. It returns files unchanged when no scope was requested.
+func (s *gitScope) apply(root string, files []scanner.Info) ([]scanner.Info, error) {
+	if !s.opts.Enabled() {
+		if s.hunks {
+			return nil, fmt.Errorf("--hunks requires --since or --staged")
+		}
+		return files, nil
+	}
+
+	changes, err := git.Diff(root, s.opts)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+
+	s.changes = make(map[string][]git.LineRange, len(changes))
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		s.changes[c.Path] = c.Lines
+		paths = append(paths, c.Path)
+	}
+
+	files = scanner.KeepPaths(root, files, paths)
+	if !s.hunks {
+		return files, nil
+	}
+
+	// Drop files whose hunks only touch imports, comments or blank lines.
+	var touched []scanner.Info
+	for _, f := range files {
+		decls, err := s.touchedDecls(root, f.Path)
+		if err == nil && len(decls) > 0 {
+			touched = append(touched, f)
+		}
+	}
+	return touched, nil
+}
+
+// touchedDecls parses a Go file and returns its declarations, limited to those
+// overlapping the diff when --hunks is active. With --staged the staged version
+// is parsed, which is what will be committed and what the hunks refer to.
+func (s *gitScope) touchedDecls(root, path string) ([]gosrc.Decl, error) {
+	rel, _ := filepath.Rel(root, path)
+	var src []byte
+	var err error
+	if s.opts.Staged {
+		src, err = git.ShowStaged(root, rel)
+	} else {
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	decls, err := gosrc.Decls(path, src)
+	if err != nil {
+		return nil, err
+	}
+	if !s.hunks {
+		return decls, nil
+	}
+
+	ranges := s.changes[filepath.ToSlash(rel)]
+
+	var touched []gosrc.Decl
+	for _, d := range decls {
+		for _, r := range ranges {
+			if d.Overlaps(r.Start, r.End) {
+				touched = append(touched, d)
+				break
+			}
+		}
+	}
+	return touched, nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/praneeth-ayla/autocommenter/internal/config"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageSince string // Flag limiting the report to recent runs
+	usageBy    string // Flag choosing how the report groups usage
+	usageJSON  bool   // Flag printing the report as JSON
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report token usage and cost of past runs",
+	Long: `Summarize the token usage and estimated cost that generation commands
+have logged, grouped by command, model, file, project or day.
+
+Examples:
+  autocommenter usage
+  autocommenter usage --since 7d --by model
+  autocommenter usage --since 2025-06-01 --by file --json
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := parseSince(usageSince)
+		if err != nil {
+			return err
+		}
+		path, err := config.UsageLogPath()
+		if err != nil {
+			return err
+		}
+		runs, err := usage.ReadLog(path, since)
+		if err != nil {
+			return fmt.Errorf("usage log: %w", err)
+		}
+		rows, err := usage.Group(runs, usageBy)
+		if err != nil {
+			return err
+		}
+
+		if usageJSON {
+			return printJSON(rows)
+		}
+		if len(runs) == 0 {
+			fmt.Println("No usage recorded yet")
+			return nil
+		}
+
+		var total usage.Row
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\tRUNS\tREQUESTS\tPROMPT\tCACHED\tOUTPUT\tTHINKING\tCOST\n", strings.ToUpper(usageBy))
+		for _, r := range rows {
+			fmt.Fprintln(w, usageRow(r.Key, r))
+			total.Usage.Add(r.Usage)
+			total.Cost += r.Cost
+		}
+		total.Runs = len(runs)
+		fmt.Fprintln(w, usageRow("total", total))
+		return w.Flush()
+	},
+}
+
+func init() {
+	usageCmd.SilenceUsage = true
+	usageCmd.Flags().StringVar(&usageSince, "since", "30d", "Only include runs since a date (2006-01-02) or for a duration (e.g. 7d, 12h); \"all\" for every run")
+	usageCmd.Flags().StringVar(&usageBy, "by", "command", "Group by command, model, file, project or day")
+	usageCmd.Flags().BoolVar(&usageJSON, "json", false, "Print the report as JSON")
+	rootCmd.AddCommand(usageCmd)
+}
+
+// usageRow formats one report row for a tabwriter.
+func usageRow(key string, r usage.Row) string {
+	u := r.Usage
+	return fmt.Sprintf("%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s", key, r.Runs, u.Requests,
+		usage.FormatTokens(u.Prompt), usage.FormatTokens(u.Cached), usage.FormatTokens(u.Output),
+		usage.FormatTokens(u.Thinking), usage.FormatCost(r.Cost))
+}
+
+// parseSince turns a date, a duration with a d suffix for days, or "all" into a start time.
+func parseSince(s string) (time.Time, error) {
+	if s == "" || s == "all" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a date like 2006-01-02, a duration like 7d or 12h, or all", s)
+}
+
+// commandName is how runs of cmd are labelled in the usage log, e.g. "comments gen".
+func commandName(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+}
+
+// finishUsage prices what meter recorded, prints a cost summary and appends the
+// run to the usage log. Runs without requests or cache hits are neither printed nor logged.
+func finishUsage(meter *usage.Meter, rootPath string) {
+	run := meter.Run(rootPath, config.Prices())
+	if run.Usage.Requests == 0 && run.Usage.Hits == 0 {
+		return
+	}
+
+	u := run.Usage
+	fmt.Printf("\nUsage: %d requests, %s prompt tokens (%s cached), %s output, %s thinking · %s\n",
+		u.Requests, usage.FormatTokens(u.Prompt), usage.FormatTokens(u.Cached),
+		usage.FormatTokens(u.Output), usage.FormatTokens(u.Thinking), usage.FormatCost(run.Cost))
+	if u.Hits > 0 {
+		fmt.Printf("  %d responses served from the local cache\n", u.Hits)
+	}
+	if byModel, err := usage.Group([]usage.Run{run}, "model"); err == nil && len(byModel) > 1 {
+		for _, r := range byModel {
+			fmt.Printf("  %-24s %3d requests  %8s tokens  %s\n", r.Key, r.Usage.Requests, usage.FormatTokens(r.Usage.Total()), usage.FormatCost(r.Cost))
+		}
+	}
+	for _, e := range run.Entries {
+		if !e.Priced {
+			fmt.Printf("  no price configured for %s; add it under \"prices\" in ~/.autocommenter/config.json\n", e.Model)
+			break
+		}
+	}
+
+	path, err := config.UsageLogPath()
+	if err == nil {
+		err = usage.Append(path, run)
+	}
+	if err != nil {
+		fmt.Println("usage log error:", err)
+	}
+}
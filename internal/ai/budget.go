@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/tokens"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+)
+
+// ErrBudgetExceeded is returned instead of sending a request that would take a
+// run past one of its limits.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Limits caps what one command run may spend. Zero means no limit.
+type Limits struct {
+	MaxTokens   int     // Prompt, output and thinking tokens.
+	MaxCost     float64 // US dollars at the configured prices.
+	MaxRequests int
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// Budget enforces Limits over a run. Spending is read from the run's meter, and
+// requests in flight are counted at their estimated size until they finish.
+// Once a request is refused, every later one is refused too, so a run stops
+// rather than squeezing in smaller requests.
+type Budget struct {
+	limits Limits
+	meter  *usage.Meter
+	prices usage.Prices
+
+	mu       sync.Mutex
+	requests int     // Requests in flight.
+	tokens   int     // Estimated tokens of requests in flight.
+	cost     float64 // Estimated cost of requests in flight.
+	err      error   // Why the budget stopped the run, once it has.
+}
+
+// NewBudget returns a budget that checks spending recorded in meter against limits.
+func NewBudget(limits Limits, meter *usage.Meter, prices usage.Prices) *Budget {
+	return &Budget{limits: limits, meter: meter, prices: prices}
+}
+
+// Err returns why the budget stopped the run, or nil if it has not.
+func (b *Budget) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// Reserve admits a request of estimated usage u on model, or returns an error
+// wrapping ErrBudgetExceeded. The returned func releases the reservation.
+func (b *Budget) Reserve(model string, u usage.Usage) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	spent, spentCost := b.meter.Totals(b.prices)
+	cost, _ := b.prices.Cost(model, u)
+	l := b.limits
+	switch {
+	case l.MaxRequests > 0 && spent.Requests+b.requests+1 > l.MaxRequests:
+		b.err = fmt.Errorf("%w: request limit of %d reached", ErrBudgetExceeded, l.MaxRequests)
+	case l.MaxTokens > 0 && spent.Total()+b.tokens+u.Total() > l.MaxTokens:
+		b.err = fmt.Errorf("%w: about %s more tokens would pass the limit of %s (%s used)", ErrBudgetExceeded,
+			usage.FormatTokens(u.Total()), usage.FormatTokens(l.MaxTokens), usage.FormatTokens(spent.Total()))
+	case l.MaxCost > 0 && spentCost+b.cost+cost > l.MaxCost:
+		b.err = fmt.Errorf("%w: about %s more would pass the limit of %s (%s spent)", ErrBudgetExceeded,
+			usage.FormatCost(cost), usage.FormatCost(l.MaxCost), usage.FormatCost(spentCost))
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	b.requests++
+	b.tokens += u.Total()
+	b.cost += cost
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.requests--
+		b.tokens -= u.Total()
+		b.cost -= cost
+	}, nil
+}
+
+// WithBudget returns p with every request checked against b first. A nil budget
+// or one without limits returns p unchanged.
+func WithBudget(p Provider, b *Budget) Provider {
+	if b == nil || b.limits.IsZero() {
+		return p
+	}
+	return &budgeted{Provider: p, budget: b}
+}
+
+// budgeted is a Provider that refuses requests its budget cannot afford.
+type budgeted struct {
+	Provider
+	budget *Budget
+}
+
+func (p *budgeted) TokenEstimator() tokens.Estimator { return EstimatorFor(p.Provider) }
+func (p *budgeted) Model(task string) string         { return ModelFor(p.Provider, task) }
+func (p *budgeted) Local() bool                      { return IsLocal(p.Provider) }
+
+// limit returns ctx with every request the provider sends checked against the
+// budget. The first request sent is the one u estimates; later ones, such as a
+// repair request on a different model, are reserved at their own size. Answers
+// from the response cache are never sent, so they need no reservation.
+func (p *budgeted) limit(ctx context.Context, u usage.Usage) context.Context {
+	return usage.WithLimiter(ctx, &call{budget: p.budget, first: u})
+}
+
+// call reserves the requests of one provider call.
+type call struct {
+	budget *Budget
+	first  usage.Usage // Estimated by the caller, who knows the whole call.
+	sent   atomic.Bool
+}
+
+func (c *call) Reserve(model string, u usage.Usage) (func(), error) {
+	if c.sent.CompareAndSwap(false, true) {
+		u = c.first
+	}
+	return c.budget.Reserve(model, u)
+}
+
+func (p *budgeted) GenerateContextBatch(ctx context.Context, files []scanner.Data) ([]contextstore.FileDetails, error) {
+	return p.Provider.GenerateContextBatch(p.limit(ctx, EstimateContextBatch(p.Provider, files)), files)
+}
+
+func (p *budgeted) GenerateOverview(ctx context.Context, pending contextstore.Hierarchy) ([]contextstore.PackageDetails, contextstore.ModuleDetails, error) {
+	u, err := EstimateOverview(p.Provider, pending)
+	if err != nil {
+		return nil, contextstore.ModuleDetails{}, err
+	}
+	return p.Provider.GenerateOverview(p.limit(ctx, u), pending)
+}
+
+func (p *budgeted) GenerateComments(ctx context.Context, content string, contexts contextstore.Hierarchy, style string) (string, error) {
+	u, err := EstimateComments(p.Provider, content, contexts, style)
+	if err != nil {
+		return "", err
+	}
+	return p.Provider.GenerateComments(p.limit(ctx, u), content, contexts, style)
+}
+
+func (p *budgeted) GenerateReadme(ctx context.Context, contexts contextstore.Hierarchy, existingReadme string) (string, error) {
+	// The provider adds the file tree itself; leaving it out only slightly undercounts.
+	u, err := EstimateReadme(p.Provider, contexts, existingReadme, "")
+	if err != nil {
+		return "", err
+	}
+	return p.Provider.GenerateReadme(p.limit(ctx, u), contexts, existingReadme)
+}
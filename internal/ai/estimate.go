@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/prompt"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+)
+
+// Expected response sizes, for when a request must be sized before it is sent.
+const (
+	ContextOutputTokens   = 300  // Per file: its summary plus the facts the model echoes back.
+	OverviewPackageTokens = 150  // Per package summary.
+	OverviewModuleTokens  = 400  // For the module overview.
+	ReadmeOutputTokens    = 2500 // For a README when there is no existing one to go by.
+)
+
+// EstimateContextBatch estimates the usage of a GenerateContextBatch request.
+func EstimateContextBatch(p Provider, files []scanner.Data) usage.Usage {
+	est := EstimatorFor(p)
+	u := usage.Usage{Requests: 1, Output: ContextOutputTokens * len(files)}
+	for _, f := range files {
+		u.Prompt += est.Estimate(prompt.BuildFileContextPrompt(f.Label(), f.Content))
+	}
+	return u
+}
+
+// EstimateOverview estimates the usage of a GenerateOverview request.
+func EstimateOverview(p Provider, pending contextstore.Hierarchy) (usage.Usage, error) {
+	text, err := prompt.BuildOverviewPrompt(pending)
+	if err != nil {
+		return usage.Usage{}, err
+	}
+	est := EstimatorFor(p)
+	return usage.Usage{
+		Requests: 1,
+		Prompt:   est.Estimate(prompt.SystemInstructionOverview) + est.Estimate(text),
+		Output:   OverviewPackageTokens*len(pending.Packages) + OverviewModuleTokens,
+	}, nil
+}
+
+// EstimateComments estimates the usage of a GenerateComments request. The
+// response is the whole file with comments added.
+func EstimateComments(p Provider, content string, contexts contextstore.Hierarchy, style string) (usage.Usage, error) {
+	ctxParts, err := prompt.EncodeHierarchy(contexts)
+	if err != nil {
+		return usage.Usage{}, err
+	}
+	text, err := prompt.BuildCommentPrompt(style, content, ctxParts)
+	if err != nil {
+		return usage.Usage{}, err
+	}
+	est := EstimatorFor(p)
+	return usage.Usage{
+		Requests: 1,
+		Prompt:   est.Estimate(prompt.SystemInstructionComments) + est.Estimate(text),
+		Output:   est.Estimate(content) * 5 / 4,
+	}, nil
+}
+
+// EstimateReadme estimates the usage of a GenerateReadme request, given the
+// file tree the provider will include.
+func EstimateReadme(p Provider, contexts contextstore.Hierarchy, existingReadme, tree string) (usage.Usage, error) {
+	text, err := prompt.BuildReadmePrompt(contexts, existingReadme, tree)
+	if err != nil {
+		return usage.Usage{}, err
+	}
+	est := EstimatorFor(p)
+	return usage.Usage{
+		Requests: 1,
+		Prompt:   est.Estimate(prompt.SystemInstructionReadme) + est.Estimate(text),
+		Output:   max(est.Estimate(existingReadme), ReadmeOutputTokens),
+	}, nil
+}
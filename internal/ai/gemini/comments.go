@@ -2,7 +2,6 @@ package gemini
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"go/parser"
 	"go/token"
@@ -14,23 +13,24 @@ import (
 	"google.golang.org/genai"
 )
 
-func (g *GeminiProvider) GenerateComments(content string, contexts []contextstore.FileDetails, style string) (string, error) {
-	ctx := context.Background()
+const (
+	commentsModel = "gemini-2.5-flash-lite" // Model that comments a file.
+	fixesModel    = "gemini-2.5-pro"        // Model that repairs output which changed code.
+)
 
+func (g *GeminiProvider) GenerateComments(ctx context.Context, content string, contexts contextstore.Hierarchy, style string) (string, error) {
 	client, err := genai.NewClient(ctx, nil)
 	if err != nil {
 		return "", err
 	}
 
-	var ctxParts strings.Builder
-	// Marshal file details into JSON and append to the context string builder.
-	for _, c := range contexts {
-		j, _ := json.Marshal(c)
-		ctxParts.Write(j)
-		ctxParts.WriteByte('\n')
+	// Encode module, package and file context as JSON lines.
+	ctxParts, err := prompt.EncodeHierarchy(contexts)
+	if err != nil {
+		return "", err
 	}
 	// Build the prompt for generating comments, including content and context.
-	promptText, err := prompt.BuildCommentPrompt(style, content, ctxParts.String())
+	promptText, err := prompt.BuildCommentPrompt(style, content, ctxParts)
 	if err != nil {
 		return "", err
 	}
@@ -44,12 +44,11 @@ func (g *GeminiProvider) GenerateComments(content string, contexts []contextstor
 
 	input := []*genai.Content{{Parts: []*genai.Part{{Text: promptText}}}}
 
-	result, err := client.Models.GenerateContent(ctx, "gemini-2.5-flash-lite", input, config)
+	out, err := generate(ctx, client, commentsModel, input, config, nil)
 	if err != nil {
 		return "", err
 	}
 
-	out := result.Text()
 	out = providerutil.StripCodeFences(out)
 	out = providerutil.EnsurePackageLine(out, content)
 
@@ -66,7 +65,7 @@ func (g *GeminiProvider) GenerateComments(content string, contexts []contextstor
 		var fixed string
 
 		for attempt := 1; attempt <= maxFixAttempts; attempt++ {
-			fixed, lastErr = applyAIFixes(content, out)
+			fixed, lastErr = applyAIFixes(ctx, content, out)
 			if lastErr != nil {
 				// applyAIFixes already returns parse errors; we can retry if < attempts
 				out = fixed // try next round with whatever AI returned (if any)
@@ -101,9 +100,7 @@ func (g *GeminiProvider) GenerateComments(content string, contexts []contextstor
 	return out, nil
 }
 
-func applyAIFixes(original string, aiOutput string) (string, error) {
-	ctx := context.Background()
-
+func applyAIFixes(ctx context.Context, original string, aiOutput string) (string, error) {
 	client, err := genai.NewClient(ctx, nil)
 	if err != nil {
 		return "", err
@@ -120,12 +117,17 @@ func applyAIFixes(original string, aiOutput string) (string, error) {
 
 	input := []*genai.Content{{Parts: []*genai.Part{{Text: promptText}}}}
 
-	result, err := client.Models.GenerateContent(ctx, "gemini-2.5-pro", input, config)
+	// Only cache fixes that parse, so a rerun asks again rather than replaying a broken one.
+	parses := func(out string) bool {
+		out = providerutil.EnsurePackageLine(providerutil.StripCodeFences(out), original)
+		_, err := parser.ParseFile(token.NewFileSet(), "", out, 0)
+		return err == nil
+	}
+	fixed, err := generate(ctx, client, fixesModel, input, config, parses)
 	if err != nil {
 		return "", err
 	}
 
-	fixed := result.Text()
 	fixed = providerutil.StripCodeFences(fixed)
 	fixed = providerutil.EnsurePackageLine(fixed, original)
 
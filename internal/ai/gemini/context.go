@@ -10,9 +10,10 @@ import (
 	"google.golang.org/genai"
 )
 
-func (g *GeminiProvider) GenerateContextBatch(files []scanner.Data) ([]contextstore.FileDetails, error) {
-	ctx := context.Background()
+// contextModel is the model used for per-file context summaries.
+const contextModel = "gemini-2.5-flash"
 
+func (g *GeminiProvider) GenerateContextBatch(ctx context.Context, files []scanner.Data) ([]contextstore.FileDetails, error) {
 	client, err := genai.NewClient(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -21,7 +22,7 @@ func (g *GeminiProvider) GenerateContextBatch(files []scanner.Data) ([]contextst
 	// Build all parts for the batch
 	var parts []*genai.Part
 	for _, f := range files {
-		promptText := prompt.BuildFileContextPrompt(f.Path, f.Content)
+		promptText := prompt.BuildFileContextPrompt(f.Label(), f.Content) // Parts of split files are labelled as such.
 		parts = append(parts, &genai.Part{Text: promptText})
 	}
 
@@ -39,19 +40,11 @@ func (g *GeminiProvider) GenerateContextBatch(files []scanner.Data) ([]contextst
 		{Parts: parts},
 	}
 
-	result, err := client.Models.GenerateContent(
-		ctx,
-		"gemini-2.5-flash",
-		// "gemini-2.5-flash-lite",
-		input,
-		config,
-	)
+	raw, err := generate(ctx, client, contextModel, input, config, validJSON)
 	if err != nil {
 		return nil, err
 	}
 
-	raw := result.Text()
-
 	var parsed struct {
 		Files []contextstore.FileDetails `json:"files"`
 	}
@@ -62,5 +55,9 @@ func (g *GeminiProvider) GenerateContextBatch(files []scanner.Data) ([]contextst
 		return nil, err
 	}
 
+	for i := range parsed.Files {
+		parsed.Files[i].Model = contextModel // Record which model produced each entry.
+	}
+
 	return parsed.Files, nil
 }
@@ -0,0 +1,56 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/prompt"
+	"google.golang.org/genai"
+)
+
+// overviewModel is the model used for package summaries and the module overview.
+const overviewModel = "gemini-2.5-flash"
+
+func (g *GeminiProvider) GenerateOverview(ctx context.Context, pending contextstore.Hierarchy) ([]contextstore.PackageDetails, contextstore.ModuleDetails, error) {
+	client, err := genai.NewClient(ctx, nil)
+	if err != nil {
+		return nil, contextstore.ModuleDetails{}, err
+	}
+
+	promptText, err := prompt.BuildOverviewPrompt(pending)
+	if err != nil {
+		return nil, contextstore.ModuleDetails{}, err
+	}
+
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: &genai.Content{
+			Parts: []*genai.Part{{Text: prompt.SystemInstructionOverview}},
+		},
+		ResponseMIMEType:   "application/json",
+		ResponseJsonSchema: GenerateOverviewSchema,
+	}
+
+	input := []*genai.Content{{Parts: []*genai.Part{{Text: promptText}}}}
+
+	raw, err := generate(ctx, client, overviewModel, input, config, validJSON)
+	if err != nil {
+		return nil, contextstore.ModuleDetails{}, err
+	}
+
+	var parsed struct {
+		Packages []contextstore.PackageDetails `json:"packages"`
+		Module   contextstore.ModuleDetails    `json:"module"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, contextstore.ModuleDetails{}, err
+	}
+
+	// Record which model produced the summaries.
+	for i := range parsed.Packages {
+		parsed.Packages[i].Model = overviewModel
+	}
+	parsed.Module.Model = overviewModel
+
+	return parsed.Packages, parsed.Module, nil
+}
@@ -22,4 +22,19 @@ func (g *GeminiProvider) Validate() error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Model returns the model used for an ai.Task* name, or "" for an unknown task.
+func (g *GeminiProvider) Model(task string) string {
+	switch task {
+	case "context":
+		return contextModel
+	case "overview":
+		return overviewModel
+	case "comments":
+		return commentsModel
+	case "readme":
+		return readmeModel
+	}
+	return ""
+}
@@ -5,20 +5,23 @@ import (
 
 	"github.com/praneeth-ayla/autocommenter/internal/ai/providerutil"
 	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
 	"github.com/praneeth-ayla/autocommenter/internal/prompt"
 	"github.com/praneeth-ayla/autocommenter/internal/scanner"
 	"google.golang.org/genai"
 )
 
-func (g *GeminiProvider) GenerateReadme(contexts []contextstore.FileDetails, existingReadme string) (string, error) {
-	ctx := context.Background()
+// readmeModel is the model used for README generation.
+const readmeModel = "gemini-2.5-pro"
 
+func (g *GeminiProvider) GenerateReadme(ctx context.Context, contexts contextstore.Hierarchy, existingReadme string) (string, error) {
 	client, err := genai.NewClient(ctx, nil) // Initialize the Gemini client.
 	if err != nil {
 		return "", err
 	}
 
-	tree, err := providerutil.BuildFileTree(scanner.GetProjectRoot()) // Build a file tree representation of the project.
+	// Build a file tree representation of the project, without paths the egress policy withholds.
+	tree, err := providerutil.BuildFileTree(scanner.GetProjectRoot(), policy.GateFromContext(ctx).Allows)
 	if err != nil {
 		return "", err
 	}
@@ -41,17 +44,18 @@ func (g *GeminiProvider) GenerateReadme(contexts []contextstore.FileDetails, exi
 		{Parts: []*genai.Part{{Text: promptText}}}, // Provide the generated prompt as input.
 	}
 
-	result, err := client.Models.GenerateContent(
+	out, err := generate(
 		ctx,
-		"gemini-2.5-pro", // Specify the Gemini model to use.
+		client,
+		readmeModel,
 		input,
 		config,
+		nil,
 	)
 	if err != nil {
 		return "", err
 	}
 
-	out := result.Text()
 	// Add attribution footer
 	out += "\n\n---\n*This README was automatically generated by [autocommenter](https://github.com/praneeth-ayla/autocommenter)*" // Append an attribution footer to the generated README.
 
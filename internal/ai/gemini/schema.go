@@ -50,13 +50,41 @@ var GenerateContextBatchSchema = map[string]any{
 				},
 				"required": []string{
 					"path",
-					"file_name",
-					"exports",
-					"imports",
 					"summary",
-				}, // All these fields are mandatory for context generation.
+				}, // Exports and imports are only needed for non-Go files; Go facts come from the AST.
 			},
 		},
 	},
 	"required": []string{"files"}, // The 'files' field is required.
-}
\ No newline at end of file
+}
+
+var GenerateOverviewSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"packages": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type": "string", // Package directory, as given in the context.
+					},
+					"summary": map[string]any{
+						"type": "string", // What the package is for.
+					},
+				},
+				"required": []string{"path", "summary"},
+			},
+		},
+		"module": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"summary": map[string]any{
+					"type": "string", // Overview of the whole module.
+				},
+			},
+			"required": []string{"summary"},
+		},
+	},
+	"required": []string{"packages", "module"}, // Both tiers are returned in one call.
+}
@@ -0,0 +1,115 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/praneeth-ayla/autocommenter/internal/cache"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
+	"github.com/praneeth-ayla/autocommenter/internal/progress"
+	"github.com/praneeth-ayla/autocommenter/internal/tokens"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+	"google.golang.org/genai"
+)
+
+// generate streams a response and returns its full text. Tokens received so far
+// are reported to the progress task in ctx, and the final usage to its meter.
+// When ctx carries a response cache, an identical earlier request is answered
+// from it, and a new response is stored in it if valid accepts it (nil accepts
+// any non-empty response), so a malformed response is not replayed on retry.
+func generate(ctx context.Context, client *genai.Client, model string, input []*genai.Content, config *genai.GenerateContentConfig, valid func(string) bool) (string, error) {
+	task := progress.FromContext(ctx)
+	responses := cache.FromContext(ctx)
+	var key string
+	if responses != nil {
+		// The config carries the system instruction, response schema and sampling parameters.
+		var err error
+		if key, err = cache.Key("gemini", model, input, config); err != nil {
+			return "", err
+		}
+		if e, ok := responses.Get(key); ok {
+			task.SetStatus("cached")
+			usage.Record(ctx, model, usage.Usage{Hits: 1})
+			return e.Text, nil
+		}
+	}
+	estimator := (&GeminiProvider{}).TokenEstimator()
+	release, err := usage.Reserve(ctx, model, requestEstimate(estimator, input, config))
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	if err := policy.Sending(ctx, model); err != nil {
+		return "", err
+	}
+	task.SetStatus("generating")
+
+	var out strings.Builder
+	var last *genai.GenerateContentResponseUsageMetadata
+	for resp, err := range client.Models.GenerateContentStream(ctx, model, input, config) {
+		if err != nil {
+			// The request was sent and may be billed even though it failed.
+			usage.Record(ctx, model, streamUsage(last))
+			return "", err
+		}
+		text := resp.Text()
+		out.WriteString(text)
+
+		// Usage counts are cumulative; estimate from the text when a chunk has none.
+		if u := resp.UsageMetadata; u != nil && u.CandidatesTokenCount+u.ThoughtsTokenCount > 0 {
+			task.SetTokens(int(u.CandidatesTokenCount + u.ThoughtsTokenCount))
+			last = u
+		} else {
+			task.AddTokens(estimator.Estimate(text))
+		}
+	}
+
+	usage.Record(ctx, model, streamUsage(last))
+
+	text := out.String()
+	if responses != nil && strings.TrimSpace(text) != "" && (valid == nil || valid(text)) {
+		if err := responses.Put(key, cache.Entry{Provider: "gemini", Model: model, Text: text, CreatedAt: time.Now().UTC()}); err != nil {
+			fmt.Fprintln(os.Stderr, "response cache error:", err)
+		}
+	}
+	return text, nil
+}
+
+// streamUsage converts the last usage counts a stream reported, if any, into
+// the usage of one request.
+func streamUsage(last *genai.GenerateContentResponseUsageMetadata) usage.Usage {
+	u := usage.Usage{Requests: 1}
+	if last != nil {
+		u.Prompt = int(last.PromptTokenCount)
+		u.Cached = int(last.CachedContentTokenCount)
+		u.Output = int(last.CandidatesTokenCount)
+		u.Thinking = int(last.ThoughtsTokenCount)
+	}
+	return u
+}
+
+// requestEstimate sizes a request for the budget before it is sent. The output
+// is taken to be as long as the prompt, which bounds the repair requests made
+// with a whole file, the only ones not sized up front by the caller.
+func requestEstimate(estimator tokens.Estimator, input []*genai.Content, config *genai.GenerateContentConfig) usage.Usage {
+	contents := input
+	if config != nil && config.SystemInstruction != nil {
+		contents = append([]*genai.Content{config.SystemInstruction}, input...)
+	}
+	n := 0
+	for _, c := range contents {
+		for _, part := range c.Parts {
+			n += estimator.Estimate(part.Text)
+		}
+	}
+	return usage.Usage{Requests: 1, Prompt: n, Output: n}
+}
+
+// validJSON accepts responses that parse as JSON.
+func validJSON(text string) bool {
+	return json.Valid([]byte(text))
+}
@@ -0,0 +1,9 @@
+package gemini
+
+import "github.com/praneeth-ayla/autocommenter/internal/tokens"
+
+// TokenEstimator returns a local estimate for Gemini models, which Google
+// documents at about four characters per token.
+func (g *GeminiProvider) TokenEstimator() tokens.Estimator {
+	return tokens.Heuristic{CharsPerToken: 4}
+}
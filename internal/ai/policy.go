@@ -0,0 +1,175 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/tokens"
+)
+
+// WithPolicy returns p with every request checked against the egress policy
+// and recorded in audit as it is sent. A request carrying source the policy
+// withholds from p is refused with an error wrapping policy.ErrWithheld, and
+// stored summaries of withheld files are left out of the context sent along.
+// Make it the innermost wrapper, so the log describes content as sent, after
+// redaction. The provider logs each request it sends through policy.Sending,
+// and does not send one that cannot be logged.
+func WithPolicy(p Provider, pol *policy.Policy, audit *policy.AuditLog) Provider {
+	return &guarded{Provider: p, policy: pol, gate: pol.Gate(IsLocal(p)), audit: audit}
+}
+
+// guarded is a Provider that only sends what its egress policy allows.
+type guarded struct {
+	Provider
+	policy *policy.Policy
+	gate   *policy.Gate
+	audit  *policy.AuditLog
+}
+
+func (p *guarded) TokenEstimator() tokens.Estimator { return EstimatorFor(p.Provider) }
+func (p *guarded) Model(task string) string         { return ModelFor(p.Provider, task) }
+func (p *guarded) Local() bool                      { return IsLocal(p.Provider) }
+
+func (p *guarded) GenerateContextBatch(ctx context.Context, files []scanner.Data) ([]contextstore.FileDetails, error) {
+	e := p.entry(TaskContext)
+	var refused error
+	for _, f := range files {
+		label := scanner.Data{Path: p.policy.Rel(f.Path), Part: f.Part, Parts: f.Parts}.Label()
+		if err := p.gate.Check(f.Path); err != nil {
+			e.Withheld = append(e.Withheld, label)
+			if refused == nil {
+				refused = err
+			}
+			continue
+		}
+		e.Files = append(e.Files, policy.Describe(label, f.Content))
+	}
+	ctx, err := p.send(ctx, e, refused)
+	if err != nil {
+		return nil, err
+	}
+	return p.Provider.GenerateContextBatch(ctx, files)
+}
+
+func (p *guarded) GenerateOverview(ctx context.Context, pending contextstore.Hierarchy) ([]contextstore.PackageDetails, contextstore.ModuleDetails, error) {
+	e := p.entry(TaskOverview)
+	pending = p.withhold(pending, &e)
+	ctx, err := p.send(ctx, e, nil)
+	if err != nil {
+		return nil, contextstore.ModuleDetails{}, err
+	}
+	return p.Provider.GenerateOverview(ctx, pending)
+}
+
+func (p *guarded) GenerateComments(ctx context.Context, content string, contexts contextstore.Hierarchy, style string) (string, error) {
+	e := p.entry(TaskComments)
+	file, refused := p.source(ctx)
+	if refused == nil {
+		refused = p.gate.Check(file)
+	}
+	if refused == nil {
+		e.Files = append(e.Files, policy.Describe(file, content))
+		contexts = p.withhold(contexts, &e)
+	} else if file != "" {
+		e.Withheld = append(e.Withheld, file)
+	}
+	ctx, err := p.send(ctx, e, refused)
+	if err != nil {
+		return "", err
+	}
+	return p.Provider.GenerateComments(ctx, content, contexts, style)
+}
+
+func (p *guarded) GenerateReadme(ctx context.Context, contexts contextstore.Hierarchy, existingReadme string) (string, error) {
+	e := p.entry(TaskReadme)
+	var refused error
+	if existingReadme != "" {
+		var file string
+		if file, refused = p.source(ctx); refused == nil {
+			refused = p.gate.Check(file)
+		}
+		if refused == nil {
+			e.Files = append(e.Files, policy.Describe(file, existingReadme))
+		} else if file != "" {
+			e.Withheld = append(e.Withheld, file)
+		}
+	}
+	contexts = p.withhold(contexts, &e)
+	ctx, err := p.send(ctx, e, refused)
+	if err != nil {
+		return "", err
+	}
+	return p.Provider.GenerateReadme(ctx, contexts, existingReadme)
+}
+
+// source returns the path of the file whose content a request sends, relative
+// to the project root. Without one the policy cannot be applied, so the
+// request is refused.
+func (p *guarded) source(ctx context.Context) (string, error) {
+	path, ok := policy.SourceFromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("%w: the file being sent is unknown", policy.ErrWithheld)
+	}
+	return p.policy.Rel(path), nil
+}
+
+// entry starts the audit entry of a request for task.
+func (p *guarded) entry(task string) policy.Entry {
+	return policy.Entry{Local: p.Local(), Model: ModelFor(p.Provider, task), Task: task}
+}
+
+// send returns ctx for a request that may go ahead, carrying e to be logged by
+// the provider when the request is sent. A refused request is logged right away,
+// listing only what was withheld, and refused is returned.
+func (p *guarded) send(ctx context.Context, e policy.Entry, refused error) (context.Context, error) {
+	if refused == nil {
+		return policy.WithAudit(policy.WithGate(ctx, p.gate), p.audit, e), nil
+	}
+	e.Refused = refused.Error()
+	e.Files, e.Context = nil, nil
+	if err := p.audit.Append(e); err != nil {
+		return nil, err
+	}
+	return nil, refused
+}
+
+// withhold drops the summaries of files the gate withholds, and of packages
+// containing them, from h. The module overview is built from package summaries,
+// so it is dropped too once anything is. Kept and dropped paths are noted in e;
+// packages are listed as their directory with a trailing slash, and the module
+// overview as the module path.
+func (p *guarded) withhold(h contextstore.Hierarchy, e *policy.Entry) contextstore.Hierarchy {
+	var kept contextstore.Hierarchy
+	dropped := false
+	for _, f := range h.Files {
+		if !p.gate.Allows(f.Path) {
+			e.Withheld = append(e.Withheld, f.Path)
+			dropped = true
+			continue
+		}
+		kept.Files = append(kept.Files, f)
+		e.Context = append(e.Context, f.Path)
+	}
+	for _, pkg := range h.Packages {
+		if slices.ContainsFunc(pkg.Files, func(f string) bool { return !p.gate.Allows(f) }) {
+			e.Withheld = append(e.Withheld, pkg.Path+"/")
+			dropped = true
+			continue
+		}
+		kept.Packages = append(kept.Packages, pkg)
+		e.Context = append(e.Context, pkg.Path+"/")
+	}
+	switch {
+	case h.Module == nil:
+	case dropped:
+		e.Withheld = append(e.Withheld, h.Module.Path)
+	default:
+		kept.Module = h.Module
+		e.Context = append(e.Context, h.Module.Path)
+	}
+	return kept
+}
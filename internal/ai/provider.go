@@ -1,19 +1,74 @@
 package ai
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/praneeth-ayla/autocommenter/internal/ai/gemini"
 	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
 	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/tokens"
 )
 
-// Provider defines the interface for AI comment generation services.
+// Provider defines the interface for AI comment generation services. Providers
+// that stream their responses report progress to the task carried by ctx (see
+// progress.WithTask).
 type Provider interface {
-	Validate() error                                                                                    // Validate checks if the provider is configured correctly.
-	GenerateComments(content string, contexts []contextstore.FileDetails, style string) (string, error) // GenerateComments creates comments for the given content and contexts.
-	GenerateContextBatch(files []scanner.Data) ([]contextstore.FileDetails, error)                      // GenerateContextBatch generates context details for multiple files.
-	GenerateReadme(contexts []contextstore.FileDetails, existingReadme string) (string, error)          // GenerateReadme generates a README file based on the provided contexts.
+	Validate() error                                                                                                                         // Validate checks if the provider is configured correctly.
+	GenerateComments(ctx context.Context, content string, contexts contextstore.Hierarchy, style string) (string, error)                     // GenerateComments creates comments for the given content and contexts.
+	GenerateContextBatch(ctx context.Context, files []scanner.Data) ([]contextstore.FileDetails, error)                                      // GenerateContextBatch generates context details for multiple files.
+	GenerateOverview(ctx context.Context, pending contextstore.Hierarchy) ([]contextstore.PackageDetails, contextstore.ModuleDetails, error) // GenerateOverview writes missing package summaries and the module overview.
+	GenerateReadme(ctx context.Context, contexts contextstore.Hierarchy, existingReadme string) (string, error)                              // GenerateReadme generates a README file based on the provided contexts.
+}
+
+// TokenEstimator is implemented by providers that know how their models tokenize text.
+type TokenEstimator interface {
+	TokenEstimator() tokens.Estimator
+}
+
+// EstimatorFor returns the provider's token estimator, or the local heuristic if it has none.
+func EstimatorFor(p Provider) tokens.Estimator {
+	if te, ok := p.(TokenEstimator); ok {
+		return te.TokenEstimator()
+	}
+	return tokens.Default
+}
+
+// Tasks a provider serves, used to ask which model handles each one.
+const (
+	TaskContext  = "context"  // Per-file context summaries.
+	TaskOverview = "overview" // Package summaries and the module overview.
+	TaskComments = "comments" // Commenting one source file.
+	TaskReadme   = "readme"   // README generation.
+)
+
+// ModelLister is implemented by providers that can say which model serves a task
+// without making a request.
+type ModelLister interface {
+	Model(task string) string
+}
+
+// ModelFor returns the model the provider uses for task, or the provider-neutral
+// task name if it cannot say.
+func ModelFor(p Provider, task string) string {
+	if ml, ok := p.(ModelLister); ok {
+		if m := ml.Model(task); m != "" {
+			return m
+		}
+	}
+	return task
+}
+
+// LocalProvider is implemented by providers whose models run on this machine,
+// such as a local model server. Providers without it are treated as hosted.
+type LocalProvider interface {
+	Local() bool
+}
+
+// IsLocal reports whether the provider's requests stay on this machine.
+func IsLocal(p Provider) bool {
+	lp, ok := p.(LocalProvider)
+	return ok && lp.Local()
 }
 
 // SupportedProviders lists the names of AI providers that the application supports.
@@ -23,14 +78,9 @@ var SupportedProviders = []string{
 
 // NewProvider creates and returns a new AI provider based on the given name.
 func NewProvider(name string) (Provider, error) {
-	var p Provider
-
-	switch name {
-	case "gemini":
-		p = gemini.New() // Instantiate the Gemini AI provider.
-	default:
-		// Return an error if the provider name is not recognized.
-		return nil, fmt.Errorf("unknown provider: %s", name)
+	p, err := NewOffline(name)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate the newly created provider before returning it.
@@ -40,3 +90,14 @@ func NewProvider(name string) (Provider, error) {
 
 	return p, nil
 }
+
+// NewOffline creates the named provider without validating its configuration,
+// for work that never sends a request, such as estimates.
+func NewOffline(name string) (Provider, error) {
+	switch name {
+	case "gemini":
+		return gemini.New(), nil // Instantiate the Gemini AI provider.
+	}
+	// Return an error if the provider name is not recognized.
+	return nil, fmt.Errorf("unknown provider: %s", name)
+}
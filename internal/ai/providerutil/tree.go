@@ -4,12 +4,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
 )
 
 // BuildFileTree recursively walks a directory and builds a string
-// representation of the file tree.
-func BuildFileTree(root string) (string, error) {
+// representation of the file tree. Paths ignored by the project's ignore
+// files are left out, the same way scanner.Scan skips them, and so are paths
+// keep rejects; a nil keep rejects none.
+func BuildFileTree(root string, keep func(rel string) bool) (string, error) {
 	var builder strings.Builder
+	ignorer := scanner.NewIgnorer(root)
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -21,6 +26,13 @@ func BuildFileTree(root string) (string, error) {
 			return nil // Skip the root directory itself.
 		}
 
+		if ignorer.Ignored(rel, info.IsDir()) || keep != nil && !keep(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir // Leave out ignored directories entirely.
+			}
+			return nil
+		}
+
 		depth := strings.Count(rel, string(filepath.Separator)) // Calculate directory depth for indentation.
 		prefix := strings.Repeat("  ", depth)                  // Create indentation prefix.
 
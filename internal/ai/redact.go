@@ -0,0 +1,101 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/praneeth-ayla/autocommenter/internal/contextstore"
+	"github.com/praneeth-ayla/autocommenter/internal/policy"
+	"github.com/praneeth-ayla/autocommenter/internal/redact"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+	"github.com/praneeth-ayla/autocommenter/internal/tokens"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
+)
+
+// WithRedaction returns p with secrets in source code, README text and stored
+// context replaced by placeholders before each request, and restored in the
+// code and README it returns. Findings are collected in r. A nil redactor
+// returns p unchanged.
+func WithRedaction(p Provider, r *redact.Redactor) Provider {
+	if r == nil {
+		return p
+	}
+	return &redacted{Provider: p, redactor: r}
+}
+
+// redacted is a Provider that never sends the secrets its redactor detects.
+// Context summaries keep the placeholders, so secrets do not reach the context
+// file and, through it, later prompts.
+type redacted struct {
+	Provider
+	redactor *redact.Redactor
+}
+
+func (p *redacted) TokenEstimator() tokens.Estimator { return EstimatorFor(p.Provider) }
+func (p *redacted) Model(task string) string         { return ModelFor(p.Provider, task) }
+func (p *redacted) Local() bool                      { return IsLocal(p.Provider) }
+
+func (p *redacted) GenerateContextBatch(ctx context.Context, files []scanner.Data) ([]contextstore.FileDetails, error) {
+	clean := make([]scanner.Data, len(files))
+	for i, f := range files {
+		f.Content = p.redactor.Redact(f.Path, max(f.Line, 1), f.Content)
+		clean[i] = f
+	}
+	return p.Provider.GenerateContextBatch(ctx, clean)
+}
+
+func (p *redacted) GenerateOverview(ctx context.Context, pending contextstore.Hierarchy) ([]contextstore.PackageDetails, contextstore.ModuleDetails, error) {
+	return p.Provider.GenerateOverview(ctx, p.hierarchy(pending))
+}
+
+func (p *redacted) GenerateComments(ctx context.Context, content string, contexts contextstore.Hierarchy, style string) (string, error) {
+	out, err := p.Provider.GenerateComments(ctx, p.redactor.Redact(source(ctx), 1, content), p.hierarchy(contexts), style)
+	if err != nil {
+		return "", err
+	}
+	return p.redactor.Restore(out), nil
+}
+
+func (p *redacted) GenerateReadme(ctx context.Context, contexts contextstore.Hierarchy, existingReadme string) (string, error) {
+	out, err := p.Provider.GenerateReadme(ctx, p.hierarchy(contexts), p.redactor.Redact(source(ctx), 1, existingReadme))
+	if err != nil {
+		return "", err
+	}
+	return p.redactor.Restore(out), nil
+}
+
+// hierarchy returns a copy of h with secrets in its summaries, doc comments and
+// signatures redacted. The context file may hold entries written before
+// redaction, or doc comments read from source without a model.
+func (p *redacted) hierarchy(h contextstore.Hierarchy) contextstore.Hierarchy {
+	var out contextstore.Hierarchy
+	if h.Module != nil {
+		m := *h.Module
+		m.Summary = p.redactor.Redact(m.Path, 0, m.Summary)
+		out.Module = &m
+	}
+	for _, pkg := range h.Packages {
+		pkg.Summary = p.redactor.Redact(pkg.Path, 0, pkg.Summary)
+		pkg.Doc = p.redactor.Redact(pkg.Path, 0, pkg.Doc)
+		out.Packages = append(out.Packages, pkg)
+	}
+	for _, f := range h.Files {
+		f.Summary = p.redactor.Redact(f.Path, 0, f.Summary)
+		f.PackageDoc = p.redactor.Redact(f.Path, 0, f.PackageDoc)
+		exports := make([]contextstore.Export, len(f.Exports))
+		for i, e := range f.Exports {
+			e.Signature = p.redactor.Redact(f.Path, 0, e.Signature)
+			exports[i] = e
+		}
+		f.Exports = exports
+		out.Files = append(out.Files, f)
+	}
+	return out
+}
+
+// source names the file whose content a request sends, for findings.
+func source(ctx context.Context) string {
+	if path, ok := policy.SourceFromContext(ctx); ok {
+		return path
+	}
+	return usage.FileFromContext(ctx)
+}
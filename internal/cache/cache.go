@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used when the config sets no limits.
+const (
+	DefaultTTL     = 30 * 24 * time.Hour
+	DefaultMaxSize = 256 << 20 // Bytes.
+)
+
+// Cache stores provider responses on disk, one file per request key. Entries
+// expire once unused for a TTL, and the least recently used ones are evicted
+// once the cache grows past its size limit. An entry's last use is its file's
+// modification time. It is safe for concurrent use.
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	mu     sync.Mutex
+	pruned bool  // Whether the cache was pruned since Open.
+	size   int64 // Size on disk as of the last prune, plus entries written since.
+}
+
+// Entry is one cached response.
+type Entry struct {
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Stats describes what the cache holds.
+type Stats struct {
+	Dir     string        `json:"dir"`
+	Entries int           `json:"entries"`
+	Expired int           `json:"expired"` // Entries unused for longer than the TTL, removed on the next write.
+	Size    int64         `json:"size"`
+	MaxSize int64         `json:"max_size"`
+	TTL     time.Duration `json:"ttl"`
+	Oldest  time.Time     `json:"oldest,omitzero"`
+	Newest  time.Time     `json:"newest,omitzero"`
+}
+
+// Open returns the cache in dir. A zero ttl or maxSize uses the default.
+func Open(dir string, ttl time.Duration, maxSize int64) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Cache{dir: dir, ttl: ttl, maxSize: maxSize}
+}
+
+// Key hashes everything that determines a response, e.g. provider, model,
+// system instruction, prompt and generation parameters, into a cache key.
+func Key(parts ...any) (string, error) {
+	data, err := json.Marshal(parts)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the entry for key if there is one that has not expired.
+func (c *Cache) Get(key string) (Entry, bool) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || time.Since(info.ModTime()) > c.ttl {
+		os.Remove(path) // Unreadable or expired.
+		return Entry{}, false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now) // Marks the entry as recently used.
+	return e, true
+}
+
+// Put stores e under key. The first Put after Open, and any that takes the
+// cache past its size limit, then evicts entries until it fits again.
+func (c *Cache) Put(key string, e Entry) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	// Write through a temporary file so concurrent readers never see half an entry.
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size += int64(len(data)) // Overcounts a replaced entry, which only prunes early.
+	if c.pruned && c.size <= c.maxSize {
+		return nil
+	}
+	return c.prune()
+}
+
+// Stats reports the number and size of entries.
+func (c *Cache) Stats() (Stats, error) {
+	s := Stats{Dir: c.dir, MaxSize: c.maxSize, TTL: c.ttl}
+	files, err := c.files()
+	for _, f := range files {
+		s.Entries++
+		s.Size += f.size
+		if s.Oldest.IsZero() || f.modTime.Before(s.Oldest) {
+			s.Oldest = f.modTime
+		}
+		if f.modTime.After(s.Newest) {
+			s.Newest = f.modTime
+		}
+		if time.Since(f.modTime) > c.ttl {
+			s.Expired++
+		}
+	}
+	return s, err
+}
+
+// Clear removes every entry and returns how many there were.
+func (c *Cache) Clear() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return 0, err
+		}
+	}
+	return len(files), nil
+}
+
+// prune removes expired entries and then the least recently used ones until
+// the cache fits its size limit. Callers hold c.mu.
+func (c *Cache) prune() error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	c.pruned = true
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	for _, f := range files {
+		if total <= c.maxSize && time.Since(f.modTime) <= c.ttl {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+	c.size = total
+	return nil
+}
+
+type cacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time // Last write or hit.
+}
+
+// files lists the entries on disk. A missing cache directory holds none.
+func (c *Cache) files() ([]cacheFile, error) {
+	var files []cacheFile
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed concurrently.
+		}
+		files = append(files, cacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	return files, err
+}
+
+// path shards entries into subdirectories by the first byte of their key.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+type cacheKey struct{}
+
+// WithCache returns a context whose provider requests are served from c when possible.
+func WithCache(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, cacheKey{}, c)
+}
+
+// FromContext returns the cache carried by ctx, or nil when caching is off.
+func FromContext(ctx context.Context) *Cache {
+	c, _ := ctx.Value(cacheKey{}).(*Cache)
+	return c
+}
@@ -5,10 +5,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/praneeth-ayla/autocommenter/internal/cache"
+	"github.com/praneeth-ayla/autocommenter/internal/usage"
 )
 
 type Config struct {
-	Provider string `json:"provider"`
+	Provider string       `json:"provider"`
+	Prices   usage.Prices `json:"prices,omitempty"` // Per-model prices overriding the built-in table.
+	Cache    CacheConfig  `json:"cache,omitzero"`
+}
+
+// CacheConfig controls the local cache of provider responses.
+type CacheConfig struct {
+	Dir       string `json:"dir,omitempty"`         // Defaults to autocommenter/responses in the user cache directory.
+	TTL       string `json:"ttl,omitempty"`         // How long a response is reused, e.g. "720h" or "30d".
+	MaxSizeMB int    `json:"max_size_mb,omitempty"` // Least recently used responses are evicted past this size.
 }
 
 // configDir determines the configuration directory path.
@@ -29,6 +44,55 @@ func configPath() (string, error) {
 	return filepath.Join(dir, "config.json"), nil // Join the config directory with the filename.
 }
 
+// UsageLogPath returns where the token usage of every run is logged.
+func UsageLogPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "usage.jsonl"), nil
+}
+
+// AuditLogPath returns where requests are logged when the egress policy names no log.
+func AuditLogPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// ResponseCache opens the response cache with the configured directory and limits.
+func ResponseCache() (*cache.Cache, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := cfg.Cache.Dir
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not determine user cache dir: %w", err)
+		}
+		dir = filepath.Join(base, "autocommenter", "responses")
+	}
+
+	var ttl time.Duration
+	if s := cfg.Cache.TTL; s != "" {
+		if days, ok := strings.CutSuffix(s, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cache ttl %q", s)
+			}
+			ttl = time.Duration(n) * 24 * time.Hour
+		} else if ttl, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("invalid cache ttl %q: %w", s, err)
+		}
+	}
+	return cache.Open(dir, ttl, int64(cfg.Cache.MaxSizeMB)<<20), nil
+}
+
 // Load reads the configuration from the file.
 func Load() (*Config, error) {
 	path, err := configPath() // Get the configuration file path.
@@ -93,7 +157,11 @@ func SetProvider(name string) error {
 	if name == "" {
 		return fmt.Errorf("provider name cannot be empty")
 	}
-	cfg := &Config{Provider: name}
+	cfg, err := Load() // Keep the other settings.
+	if err != nil {
+		cfg = &Config{}
+	}
+	cfg.Provider = name
 	return Save(cfg)
 }
 
@@ -107,4 +175,13 @@ func GetProvider() (string, error) {
 		return "gemini", nil // Return default if provider is empty in loaded config.
 	}
 	return cfg.Provider, nil
-}
\ No newline at end of file
+}
+
+// Prices returns the built-in model price table with the configured overrides applied.
+func Prices() usage.Prices {
+	cfg, err := Load()
+	if err != nil {
+		return usage.DefaultPrices
+	}
+	return usage.DefaultPrices.With(cfg.Prices)
+}
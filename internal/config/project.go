@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectFileName is the per-project configuration file looked up in the project root.
+const ProjectFileName = ".autocommenter.json"
+
+// ProjectConfig holds settings that belong to a single repository and can be committed with it.
+type ProjectConfig struct {
+	Filter  FilterConfig  `json:"filter"`
+	Hook    HookConfig    `json:"hook"`
+	Context ContextConfig `json:"context"`
+	Budget  BudgetConfig  `json:"budget"`
+}
+
+// DefaultContextPath is where project context is stored, relative to the project root.
+const DefaultContextPath = ".autocommenter/context.json"
+
+// FilterConfig controls which scanned files are considered for comment generation.
+type FilterConfig struct {
+	Include      []string `json:"include,omitempty"`       // Glob rules a file must match (any) to be included.
+	Exclude      []string `json:"exclude,omitempty"`       // Glob rules that skip a file when matched.
+	IncludeTests bool     `json:"include_tests,omitempty"` // Keep _test.go files, which are skipped by default.
+}
+
+// HookConfig controls what the git pre-commit hook does with staged Go files.
+type HookConfig struct {
+	Generate bool   `json:"generate,omitempty"` // Generate comments for files missing docs and re-stage them.
+	Style    string `json:"style,omitempty"`    // Comment style used when generating; defaults to docstring.
+	Strict   bool   `json:"strict,omitempty"`   // Block the commit while exported declarations lack docs.
+}
+
+// ContextConfig controls where generated project context is stored and how it is generated.
+type ContextConfig struct {
+	Path   string      `json:"path,omitempty"`   // Context file, relative to the project root unless absolute.
+	Budget int         `json:"budget,omitempty"` // Approximate tokens of context sent with each file; 0 uses the default.
+	Batch  BatchConfig `json:"batch"`            // Size of batched context requests.
+}
+
+// BatchConfig bounds the estimated size of each context generation request. Zero uses the default.
+type BatchConfig struct {
+	MaxInputTokens  int `json:"max_input_tokens,omitempty"`  // Prompt tokens per request.
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"` // Expected response tokens per request.
+}
+
+// BudgetConfig caps what a single generation run may spend. Zero means no limit.
+type BudgetConfig struct {
+	MaxTokens   int     `json:"max_tokens,omitempty"`   // Prompt, output and thinking tokens.
+	MaxCost     float64 `json:"max_cost,omitempty"`     // US dollars at the configured prices.
+	MaxRequests int     `json:"max_requests,omitempty"` // Requests sent to the provider.
+}
+
+// ContextFile returns the absolute path of the context file for a project rooted at root.
+func (c ContextConfig) ContextFile(root string) string {
+	path := c.Path
+	if path == "" {
+		path = DefaultContextPath
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, filepath.FromSlash(path))
+	}
+	return path
+}
+
+// LoadProject reads the project configuration from root.
+// A missing file is not an error and yields the default configuration.
+func LoadProject(root string) (*ProjectConfig, error) {
+	path := filepath.Join(root, ProjectFileName)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &ProjectConfig{}, nil // No project config, use defaults.
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg ProjectConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", ProjectFileName, err)
+	}
+	return &cfg, nil
+}
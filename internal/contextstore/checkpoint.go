@@ -0,0 +1,173 @@
+package contextstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+)
+
+// Checkpoint tracks a context generation run so an interrupted or partly failed
+// run can be resumed. It lives next to the context file and is removed once a
+// run finishes without failures. The run that wrote it owns it; another run
+// starting over takes it over.
+type Checkpoint struct {
+	Run       string            `json:"run,omitempty"`  // Identifies the owning run.
+	Host      string            `json:"host,omitempty"` // Where the owning run executes.
+	PID       int               `json:"pid,omitempty"`  // Process of the owning run.
+	StartedAt time.Time         `json:"started_at"`
+	Pending   []string          `json:"pending"` // Files the run set out to generate.
+	Done      []string          `json:"done"`    // Files whose entries have been saved.
+	Failed    map[string]string `json:"failed"`  // Files that failed, with the reason.
+}
+
+// NewCheckpoint starts a checkpoint for a run over the given project-relative paths.
+func NewCheckpoint(pending []string) *Checkpoint {
+	sorted := append([]string(nil), pending...)
+	sort.Strings(sorted)
+	host, _ := os.Hostname()
+	started := time.Now().UTC()
+	return &Checkpoint{
+		Run:       fmt.Sprintf("%d-%d", os.Getpid(), started.UnixNano()),
+		Host:      host,
+		PID:       os.Getpid(),
+		StartedAt: started,
+		Pending:   sorted,
+		Failed:    map[string]string{},
+	}
+}
+
+// Live reports whether the run that owns the checkpoint may still be running:
+// its process is alive, or it runs on another host where that cannot be told.
+// Checkpoints written before runs were recorded are never live.
+func (c *Checkpoint) Live() bool {
+	if c.PID == 0 || c.PID == os.Getpid() {
+		return false
+	}
+	if host, _ := os.Hostname(); c.Host != host {
+		return true
+	}
+	return processAlive(c.PID)
+}
+
+// MarkDone records saved files and clears any earlier failure for them.
+func (c *Checkpoint) MarkDone(paths ...string) {
+	for _, p := range paths {
+		delete(c.Failed, p)
+		if !slices.Contains(c.Done, p) {
+			c.Done = append(c.Done, p)
+		}
+	}
+}
+
+// MarkFailed records why files could not be generated.
+func (c *Checkpoint) MarkFailed(reason string, paths ...string) {
+	for _, p := range paths {
+		c.Failed[p] = reason
+	}
+}
+
+// Remaining returns the pending files not yet saved, failed ones included.
+func (c *Checkpoint) Remaining() []string {
+	var out []string
+	for _, p := range c.Pending {
+		if !slices.Contains(c.Done, p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// LoadCheckpoint reads the checkpoint of the last unfinished run. It returns
+// ErrNotFound when there is none.
+func LoadCheckpoint() (*Checkpoint, error) {
+	path, err := checkpointPath()
+	if err != nil {
+		return nil, err
+	}
+	return readCheckpoint(path)
+}
+
+// readCheckpoint decodes the checkpoint at path.
+func readCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if c.Failed == nil {
+		c.Failed = map[string]string{}
+	}
+	return &c, nil
+}
+
+// SaveCheckpoint writes the checkpoint atomically under the context file lock,
+// replacing the checkpoint of any other run.
+func SaveCheckpoint(c *Checkpoint) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return withCheckpoint(func(path string) error {
+		return scanner.WriteFileAtomic(path, string(data))
+	})
+}
+
+// RemoveCheckpoint deletes the checkpoint if c still owns it. A checkpoint
+// another run has taken over since is left in place.
+func RemoveCheckpoint(c *Checkpoint) error {
+	return withCheckpoint(func(path string) error {
+		current, err := readCheckpoint(path)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err == nil && current.Run != c.Run {
+			return nil
+		}
+		// An unreadable checkpoint cannot be resumed, so it goes too.
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// withCheckpoint calls fn with the checkpoint path while holding the context
+// file lock, so runs never interleave their checkpoint updates.
+func withCheckpoint(fn func(path string) error) error {
+	configPath, err := getConfigFilePath()
+	if err != nil {
+		return err
+	}
+	if err := ensureDir(filepath.Dir(configPath)); err != nil {
+		return err
+	}
+	unlock, err := lockFile(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn(configPath + ".checkpoint")
+}
+
+// checkpointPath returns where the checkpoint of the context file is kept.
+func checkpointPath() (string, error) {
+	path, err := getConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return path + ".checkpoint", nil
+}
@@ -0,0 +1,47 @@
+package contextstore
+
+import "sort"
+
+// Diff compares stored file entries with the files currently in the project.
+type Diff struct {
+	Missing  []string `json:"missing"`  // Files in the project without a stored entry.
+	Stale    []string `json:"stale"`    // Stored entries whose file content has changed.
+	Orphaned []string `json:"orphaned"` // Stored entries whose file no longer exists.
+}
+
+// Diff compares the store with current, a map of project-relative paths to content hashes.
+// Entries without a recorded hash count as stale, since they cannot be verified.
+func (s *Store) Diff(current map[string]string) Diff {
+	d := Diff{Missing: []string{}, Stale: []string{}, Orphaned: []string{}}
+	for path, hash := range current {
+		entry, ok := s.Files[path]
+		switch {
+		case !ok:
+			d.Missing = append(d.Missing, path)
+		case entry.Hash == "" || entry.Hash != hash:
+			d.Stale = append(d.Stale, path)
+		}
+	}
+	for path := range s.Files {
+		if _, ok := current[path]; !ok {
+			d.Orphaned = append(d.Orphaned, path)
+		}
+	}
+
+	sort.Strings(d.Missing)
+	sort.Strings(d.Stale)
+	sort.Strings(d.Orphaned)
+	return d
+}
+
+// Remove deletes the given file entries and returns the paths that existed.
+func (s *Store) Remove(paths ...string) []string {
+	var removed []string
+	for _, p := range paths {
+		if _, ok := s.Files[p]; ok {
+			delete(s.Files, p)
+			removed = append(removed, p)
+		}
+	}
+	return removed
+}
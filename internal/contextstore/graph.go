@@ -0,0 +1,177 @@
+package contextstore
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/praneeth-ayla/autocommenter/internal/gosrc"
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+)
+
+// Import kinds used on graph edges.
+const (
+	ImportStdlib   = "stdlib"   // Standard library package.
+	ImportInternal = "internal" // Package inside this module.
+	ImportExternal = "external" // Third-party module.
+)
+
+// Graph records which packages of the module import which packages.
+type Graph struct {
+	Module   string      `json:"module"`   // Module path the graph was built for.
+	Packages []GraphNode `json:"packages"` // Packages of the module, sorted by import path.
+	Edges    []GraphEdge `json:"edges"`    // Imports, sorted by source then target.
+}
+
+// GraphNode is a package of the module.
+type GraphNode struct {
+	ImportPath string `json:"import_path"`
+	Dir        string `json:"dir"` // Directory relative to the project root.
+	Name       string `json:"name"`
+}
+
+// GraphEdge is an import from a module package to another package.
+type GraphEdge struct {
+	From string `json:"from"` // Importing package path.
+	To   string `json:"to"`   // Imported package path.
+	Kind string `json:"kind"` // ImportStdlib, ImportInternal or ImportExternal.
+}
+
+// BuildGraph parses the imports of the given Go files and builds the package graph.
+// Test files are left out so test-only dependencies do not show up as edges.
+func BuildGraph(rootPath, modulePath string, files []scanner.Data) Graph {
+	g := Graph{Module: modulePath}
+	nodes := map[string]GraphNode{}
+	edges := map[GraphEdge]bool{}
+
+	for _, f := range files {
+		if filepath.Ext(f.Path) != ".go" || strings.HasSuffix(f.Path, "_test.go") {
+			continue
+		}
+		rel := scanner.RelSlash(rootPath, f.Path)
+		if scanner.MatchGlob("vendor/", rel) || scanner.MatchGlob("testdata/", rel) {
+			continue // Not packages of the module, as the go command sees it.
+		}
+		pkg, imports, err := gosrc.Imports(f.Path, []byte(f.Content))
+		if err != nil {
+			continue // Unparsable files cannot contribute imports.
+		}
+
+		dir := path.Dir(rel)
+		from := importPath(modulePath, dir)
+		if from == "" {
+			from = dir // No module path; fall back to the directory.
+		}
+		if _, ok := nodes[from]; !ok {
+			nodes[from] = GraphNode{ImportPath: from, Dir: dir, Name: pkg}
+		}
+
+		for _, to := range imports {
+			edges[GraphEdge{From: from, To: to, Kind: classifyImport(modulePath, to)}] = true
+		}
+	}
+
+	for _, n := range nodes {
+		g.Packages = append(g.Packages, n)
+	}
+	sort.Slice(g.Packages, func(i, j int) bool { return g.Packages[i].ImportPath < g.Packages[j].ImportPath })
+
+	for e := range edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
+
+// classifyImport tells module-internal, standard library and third-party imports apart.
+// Like the go command, it treats paths whose first element has no dot as standard library.
+func classifyImport(modulePath, importPath string) string {
+	if modulePath != "" && (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) {
+		return ImportInternal
+	}
+	first, _, _ := strings.Cut(importPath, "/")
+	if !strings.Contains(first, ".") {
+		return ImportStdlib
+	}
+	return ImportExternal
+}
+
+// Filter returns a copy of the graph keeping only edges of the given kinds.
+func (g Graph) Filter(kinds ...string) Graph {
+	out := Graph{Module: g.Module, Packages: g.Packages}
+	for _, e := range g.Edges {
+		for _, k := range kinds {
+			if e.Kind == k {
+				out.Edges = append(out.Edges, e)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// WriteText prints each package followed by its imports.
+func (g Graph) WriteText(w io.Writer) {
+	byFrom := map[string][]GraphEdge{}
+	for _, e := range g.Edges {
+		byFrom[e.From] = append(byFrom[e.From], e)
+	}
+	for _, n := range g.Packages {
+		fmt.Fprintf(w, "%s\n", n.ImportPath)
+		for _, e := range byFrom[n.ImportPath] {
+			fmt.Fprintf(w, "  -> %s (%s)\n", e.To, e.Kind)
+		}
+	}
+}
+
+// WriteDOT prints the graph in Graphviz DOT syntax, with internal packages as boxes.
+func (g Graph) WriteDOT(w io.Writer) {
+	fmt.Fprintln(w, "digraph imports {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	for _, n := range g.Packages {
+		fmt.Fprintf(w, "  %q [shape=box];\n", n.ImportPath)
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if e.Kind != ImportInternal {
+			style = " [style=dashed]"
+		}
+		fmt.Fprintf(w, "  %q -> %q%s;\n", e.From, e.To, style)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// WriteMermaid prints the graph as a Mermaid flowchart.
+func (g Graph) WriteMermaid(w io.Writer) {
+	ids := map[string]string{}
+	id := func(p string) string {
+		if v, ok := ids[p]; ok {
+			return v
+		}
+		ids[p] = fmt.Sprintf("n%d", len(ids))
+		return ids[p]
+	}
+
+	fmt.Fprintln(w, "graph LR")
+	for _, n := range g.Packages {
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", id(n.ImportPath), n.ImportPath)
+	}
+	for _, e := range g.Edges {
+		if _, known := ids[e.To]; !known {
+			fmt.Fprintf(w, "  %s([\"%s\"])\n", id(e.To), e.To) // Packages outside the module are rounded.
+		}
+		arrow := "-->"
+		if e.Kind != ImportInternal {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", id(e.From), arrow, id(e.To))
+	}
+}
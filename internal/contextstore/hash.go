@@ -0,0 +1,12 @@
+package contextstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashContent returns the hex SHA-256 of file content, used to detect changed files.
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,40 @@
+package contextstore
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Lock timing for the context file.
+const (
+	lockTimeout      = 2 * time.Minute        // How long to wait for another process before giving up.
+	lockPollInterval = 100 * time.Millisecond // How often to retry a held lock.
+	staleLockAge     = 10 * time.Minute       // Lock files older than this are left over from a crash (fallback lock only).
+)
+
+// lockPath returns the advisory lock file guarding the context file at path.
+func lockPath(path string) string {
+	return path + ".lock"
+}
+
+// waitForLock calls try until it acquires the lock, reports an error or the
+// timeout passes. It says once on stderr that it is waiting.
+func waitForLock(lock string, try func() (bool, error)) error {
+	deadline := time.Now().Add(lockTimeout)
+	waiting := false
+	for {
+		ok, err := try()
+		if err != nil || ok {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for %s; remove it if no other autocommenter is running", lockTimeout, lock)
+		}
+		if !waiting {
+			fmt.Fprintln(os.Stderr, "Waiting for another autocommenter process to finish with the context file...")
+			waiting = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
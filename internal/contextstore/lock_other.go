@@ -0,0 +1,46 @@
+//go:build !unix
+
+package contextstore
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockFile creates the lock file next to path exclusively and removes it on
+// release. A lock file older than staleLockAge is assumed to be left over from
+// a process that crashed and is taken over.
+func lockFile(path string) (func() error, error) {
+	lock := lockPath(path)
+	err := waitForLock(lock, func() (bool, error) {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid()) // Helps whoever finds a stale lock.
+			return true, f.Close()
+		}
+		if !os.IsExist(err) {
+			return false, err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lock)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error { return os.Remove(lock) }, nil
+}
+
+// processAlive reports whether a process with the given ID exists, as far as
+// finding it tells; on Windows that fails for processes that have exited.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
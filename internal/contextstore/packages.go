@@ -0,0 +1,182 @@
+package contextstore
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store is everything kept in the context file: per-file entries plus the
+// package and module summaries aggregated from them.
+type Store struct {
+	Files    map[string]FileDetails    `json:"files"`            // File entries keyed by project-relative path.
+	Packages map[string]PackageDetails `json:"packages"`         // Package entries keyed by project-relative directory.
+	Module   *ModuleDetails            `json:"module,omitempty"` // Overview of the whole module.
+	Graph    *Graph                    `json:"graph,omitempty"`  // Package import graph.
+
+	createdAt time.Time // When the context file was first written, kept across saves.
+}
+
+// PackageDetails summarizes one directory of Go files.
+type PackageDetails struct {
+	Path        string    `json:"path"`                  // Directory relative to the project root, "." for the root.
+	ImportPath  string    `json:"import_path,omitempty"` // Full import path inside the module.
+	Name        string    `json:"name"`                  // Package name from the package clause.
+	Doc         string    `json:"doc,omitempty"`         // Package doc comment.
+	Files       []string  `json:"files"`                 // Context keys of the package's files.
+	Summary     string    `json:"summary"`               // What the package is for.
+	Hash        string    `json:"hash,omitempty"`        // Hash of the file entries the summary was built from.
+	Model       string    `json:"model,omitempty"`       // Model that produced the summary.
+	GeneratedAt time.Time `json:"generated_at,omitzero"` // When the summary was generated.
+}
+
+// ModuleDetails is the top tier of context: what the module as a whole does.
+type ModuleDetails struct {
+	Path        string    `json:"path"`                  // Module path from go.mod.
+	Summary     string    `json:"summary"`               // Overview of the module.
+	Hash        string    `json:"hash,omitempty"`        // Hash of the package summaries it was built from.
+	Model       string    `json:"model,omitempty"`       // Model that produced the overview.
+	GeneratedAt time.Time `json:"generated_at,omitzero"` // When the overview was generated.
+}
+
+// Hierarchy is the layered view of project context handed to providers.
+type Hierarchy struct {
+	Module   *ModuleDetails
+	Packages []PackageDetails
+	Files    []FileDetails
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{Files: map[string]FileDetails{}, Packages: map[string]PackageDetails{}}
+}
+
+// normalize makes sure the maps are usable after decoding.
+func (s *Store) normalize() {
+	if s.Files == nil {
+		s.Files = map[string]FileDetails{}
+	}
+	if s.Packages == nil {
+		s.Packages = map[string]PackageDetails{}
+	}
+}
+
+// Hierarchy returns every stored entry, with packages and files sorted by path.
+func (s *Store) Hierarchy() Hierarchy {
+	h := Hierarchy{Module: s.Module, Files: MapToSlice(s.Files)}
+	for _, p := range s.Packages {
+		h.Packages = append(h.Packages, p)
+	}
+	sort.Slice(h.Packages, func(i, j int) bool { return h.Packages[i].Path < h.Packages[j].Path })
+	sort.Slice(h.Files, func(i, j int) bool { return h.Files[i].Path < h.Files[j].Path })
+	return h
+}
+
+// RefreshPackages regroups Go file entries into packages. Packages whose files
+// changed since their summary was written lose the summary, and so does the
+// module overview. It returns what a provider needs to fill the gaps: every
+// package (stale ones with an empty summary), the files of stale packages and
+// the module entry. Module is nil when everything is current.
+func (s *Store) RefreshPackages(modulePath string) Hierarchy {
+	grouped := make(map[string]*PackageDetails)
+	for _, key := range sortedKeys(s.Files) {
+		f := s.Files[key]
+		if f.Package == "" {
+			continue // Not a Go file, or generated before static extraction.
+		}
+
+		dir := path.Dir(key)
+		p, ok := grouped[dir]
+		if !ok {
+			p = &PackageDetails{Path: dir, ImportPath: importPath(modulePath, dir)}
+			grouped[dir] = p
+		}
+		if p.Name == "" || strings.HasSuffix(p.Name, "_test") {
+			p.Name = f.Package // Prefer the non-test package name.
+		}
+		if f.PackageDoc != "" {
+			p.Doc = f.PackageDoc
+		}
+		p.Files = append(p.Files, key)
+	}
+
+	var pending Hierarchy
+	var hashes []string
+	for _, dir := range sortedKeys(grouped) {
+		p := grouped[dir]
+		p.Hash = s.packageHash(p.Files)
+		hashes = append(hashes, dir+":"+p.Hash)
+
+		if old, ok := s.Packages[dir]; ok && old.Hash == p.Hash && old.Summary != "" {
+			p.Summary, p.Model, p.GeneratedAt = old.Summary, old.Model, old.GeneratedAt
+		} else {
+			for _, key := range p.Files {
+				pending.Files = append(pending.Files, s.Files[key])
+			}
+		}
+	}
+
+	s.Packages = make(map[string]PackageDetails, len(grouped))
+	for dir, p := range grouped {
+		s.Packages[dir] = *p
+	}
+
+	moduleHash := HashContent(strings.Join(hashes, "\n"))
+	moduleStale := s.Module == nil || s.Module.Hash != moduleHash || s.Module.Path != modulePath || s.Module.Summary == ""
+	if moduleStale || len(pending.Files) > 0 {
+		s.Module = &ModuleDetails{Path: modulePath, Hash: moduleHash}
+		pending.Module = s.Module
+		pending.Packages = s.Hierarchy().Packages
+	}
+	return pending
+}
+
+// ApplyOverview stores generated package summaries and the module overview.
+// Each must carry the Hash of the entry it was generated for; summaries whose
+// package or module changed since then are dropped, as they describe older files.
+func (s *Store) ApplyOverview(packages []PackageDetails, module ModuleDetails) {
+	now := time.Now().UTC()
+	for _, generated := range packages {
+		p, ok := s.Packages[generated.Path]
+		if !ok || generated.Summary == "" || p.Hash != generated.Hash {
+			continue // Unknown path, nothing produced, or out of date.
+		}
+		p.Summary, p.Model, p.GeneratedAt = generated.Summary, generated.Model, now
+		s.Packages[p.Path] = p
+	}
+
+	if s.Module != nil && module.Summary != "" && s.Module.Hash == module.Hash {
+		s.Module.Summary, s.Module.Model, s.Module.GeneratedAt = module.Summary, module.Model, now
+	}
+}
+
+// packageHash combines the content hashes and summaries of a package's files.
+func (s *Store) packageHash(files []string) string {
+	var sb strings.Builder
+	for _, key := range files {
+		f := s.Files[key]
+		sb.WriteString(key + "\x00" + f.Hash + "\x00" + f.Summary + "\n")
+	}
+	return HashContent(sb.String())
+}
+
+// importPath joins the module path and a relative directory.
+func importPath(modulePath, dir string) string {
+	if modulePath == "" {
+		return ""
+	}
+	if dir == "." {
+		return modulePath
+	}
+	return modulePath + "/" + dir
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+package contextstore
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+)
+
+// Reconciliation is a provider's batch output checked against the files that
+// were sent. Only entries that match a requested file are kept.
+type Reconciliation struct {
+	Files      []FileDetails  // Accepted entries, in request order, with Path set to the requested path.
+	Missing    []scanner.Data // Requested files no entry was returned for.
+	Unknown    []string       // Returned paths that match no requested file; their entries are dropped.
+	Duplicates []string       // Requested paths returned more than once; only one entry is kept.
+	Empty      []string       // Requested paths whose accepted entry has an empty summary.
+}
+
+// Reconcile matches the entries in got to the requested files. A returned path
+// matches a file when, once cleaned, it equals the file's path or label, or when
+// it is a relative path that ends exactly one requested path or label. Among
+// duplicates the first entry with a summary wins.
+func Reconcile(requested []scanner.Data, got []FileDetails) Reconciliation {
+	var r Reconciliation
+	matched := make([]*FileDetails, len(requested))
+	duplicate := make([]bool, len(requested))
+
+	for _, item := range got {
+		i := matchRequested(requested, item.Path)
+		if i < 0 {
+			r.Unknown = append(r.Unknown, item.Path)
+			continue
+		}
+		if prev := matched[i]; prev != nil {
+			duplicate[i] = true
+			if strings.TrimSpace(prev.Summary) != "" || strings.TrimSpace(item.Summary) == "" {
+				continue
+			}
+		}
+		item.Path = requested[i].Path
+		matched[i] = &item
+	}
+
+	for i, d := range requested {
+		if duplicate[i] {
+			r.Duplicates = append(r.Duplicates, d.Path)
+		}
+		item := matched[i]
+		if item == nil {
+			r.Missing = append(r.Missing, d)
+			continue
+		}
+		if strings.TrimSpace(item.Summary) == "" {
+			r.Empty = append(r.Empty, d.Path)
+		}
+		r.Files = append(r.Files, *item)
+	}
+	return r
+}
+
+// Merge folds the reconciliation of a retried request for files missing from r into r.
+func (r *Reconciliation) Merge(retry Reconciliation) {
+	retried := make(map[string]bool, len(retry.Files))
+	for _, f := range retry.Files {
+		retried[f.Path] = true
+	}
+	var missing []scanner.Data
+	for _, d := range r.Missing {
+		if !retried[d.Path] {
+			missing = append(missing, d)
+		}
+	}
+
+	r.Files = append(r.Files, retry.Files...)
+	r.Missing = missing
+	r.Unknown = append(r.Unknown, retry.Unknown...)
+	r.Duplicates = append(r.Duplicates, retry.Duplicates...)
+	r.Empty = append(r.Empty, retry.Empty...)
+}
+
+// matchRequested returns the index of the requested file path refers to, or -1.
+func matchRequested(requested []scanner.Data, path string) int {
+	path = cleanResultPath(path)
+	if path == "" || path == "." {
+		return -1
+	}
+	for i, d := range requested {
+		if path == cleanResultPath(d.Path) || path == cleanResultPath(d.Label()) {
+			return i
+		}
+	}
+
+	// Models often drop the directory prefix they were given.
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "../") {
+		return -1
+	}
+	found := -1
+	for i, d := range requested {
+		if strings.HasSuffix(cleanResultPath(d.Path), "/"+path) || strings.HasSuffix(cleanResultPath(d.Label()), "/"+path) {
+			if found >= 0 && requested[found].Path != d.Path {
+				return -1 // Ambiguous.
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+// cleanResultPath normalizes a path for comparison.
+func cleanResultPath(p string) string {
+	return filepath.ToSlash(filepath.Clean(strings.TrimSpace(p)))
+}
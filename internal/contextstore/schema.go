@@ -0,0 +1,92 @@
+package contextstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the context file layout this build writes and the newest it reads.
+//
+//	0: bare map of file entries keyed by path
+//	1: object with files, packages, module and graph
+//	2: versioned envelope with module path and timestamps around version 1
+const SchemaVersion = 2
+
+// ErrNewerSchema is returned when a context file was written by a newer autocommenter.
+var ErrNewerSchema = errors.New("context file schema is newer than supported")
+
+// envelope is the on-disk layout of the context file.
+type envelope struct {
+	Version   int       `json:"version"`
+	Module    string    `json:"module"` // Module path of the project the context describes.
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Entries   *Store    `json:"entries"`
+}
+
+// migrations upgrade a context file one version at a time; migrations[v] turns
+// version v into version v+1. Add a step here whenever the layout changes.
+var migrations = []func(data []byte) ([]byte, error){
+	// 0 → 1: wrap the bare file map.
+	func(data []byte) ([]byte, error) {
+		var files map[string]json.RawMessage
+		if err := json.Unmarshal(data, &files); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{"files": files})
+	},
+	// 1 → 2: wrap the store in the envelope. The creation time is unknown.
+	func(data []byte) ([]byte, error) {
+		return json.Marshal(map[string]any{"version": 2, "entries": json.RawMessage(data)})
+	},
+}
+
+// detectVersion works out the schema version of a context file.
+func detectVersion(data []byte) (int, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return 0, err
+	}
+	if raw, ok := probe["version"]; ok {
+		var v int
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return 0, fmt.Errorf("invalid schema version %s", raw)
+		}
+		return v, nil
+	}
+	if _, ok := probe["files"]; ok {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// decode upgrades data to the current schema and decodes it. It also returns the
+// version the data was written in, so callers can tell when a rewrite is due.
+func decode(data []byte) (*envelope, int, error) {
+	version, err := detectVersion(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > SchemaVersion {
+		return nil, version, fmt.Errorf("%w: file has version %d, this build supports up to %d; upgrade autocommenter", ErrNewerSchema, version, SchemaVersion)
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		if data, err = migrations[v](data); err != nil {
+			return nil, version, fmt.Errorf("migrate schema %d to %d: %w", v, v+1, err)
+		}
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, version, err
+	}
+	if env.Entries == nil {
+		env.Entries = NewStore()
+	}
+	env.Entries.normalize()
+	env.Entries.createdAt = env.CreatedAt
+	return &env, version, nil
+}
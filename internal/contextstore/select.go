@@ -0,0 +1,230 @@
+package contextstore
+
+import (
+	"encoding/json"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/praneeth-ayla/autocommenter/internal/tokens"
+)
+
+// DefaultContextBudget is the approximate number of tokens of context sent with each file.
+const DefaultContextBudget = 8000
+
+// Relevance weights for the signals Select combines.
+const (
+	weightSelf     = 10.0 // The target file's own entry.
+	weightSamePkg  = 3.0  // Files in the same package.
+	weightImported = 2.0  // Files in packages the target imports.
+	weightImporter = 1.5  // Files in packages that import the target's package.
+	weightLexical  = 2.0  // Scaled BM25 similarity between the target source and a file's summary and exports.
+	bm25K1, bm25B  = 1.2, 0.75
+	minTokenLength = 3
+)
+
+// Select picks the context most relevant to the file at target (a project-relative
+// path) whose source is content, keeping the encoded result under roughly budget
+// tokens. Files are ranked by package relationships and by lexical similarity of
+// their summaries and exports to the target source. The module overview is always
+// kept, and each selected file brings its package summary along while it fits.
+// A budget of zero or less keeps everything.
+func (h Hierarchy) Select(target, content string, budget int) Hierarchy {
+	if budget <= 0 {
+		return h
+	}
+
+	targetDir := path.Dir(target)
+	targetImports := h.importsOf(target, content)
+	targetImportPath := h.importPathOf(targetDir)
+
+	lexical := bm25(tokenize(content), h.Files)
+	maxLexical := 0.0
+	for _, score := range lexical {
+		maxLexical = math.Max(maxLexical, score)
+	}
+
+	type ranked struct {
+		file  FileDetails
+		score float64
+	}
+	candidates := make([]ranked, 0, len(h.Files))
+	for i, f := range h.Files {
+		score := 0.0
+		dir := path.Dir(f.Path)
+		switch {
+		case f.Path == target:
+			score += weightSelf
+		case dir == targetDir:
+			score += weightSamePkg
+		}
+		if ip := h.importPathOf(dir); ip != "" && slices.Contains(targetImports, ip) {
+			score += weightImported
+		}
+		if targetImportPath != "" && slices.Contains(f.Imports, targetImportPath) {
+			score += weightImporter
+		}
+		if maxLexical > 0 {
+			score += weightLexical * lexical[i] / maxLexical
+		}
+		candidates = append(candidates, ranked{f, score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	out := Hierarchy{Module: h.Module}
+	used := approxTokens(h.Module)
+
+	packages := make(map[string]PackageDetails, len(h.Packages))
+	for _, p := range h.Packages {
+		packages[p.Path] = p
+	}
+
+	addedDirs := map[string]bool{}
+	for _, c := range candidates {
+		if c.score <= 0 {
+			break // Unrelated files are not worth any budget.
+		}
+		cost := approxTokens(c.file)
+		if used+cost > budget {
+			continue // A smaller, lower-ranked file may still fit.
+		}
+		used += cost
+		out.Files = append(out.Files, c.file)
+
+		// The package summary describes a selected file's surroundings; add it the first time it is needed.
+		dir := path.Dir(c.file.Path)
+		if p, ok := packages[dir]; ok && !addedDirs[dir] {
+			if pkgCost := approxTokens(p); used+pkgCost <= budget {
+				used += pkgCost
+				out.Packages = append(out.Packages, p)
+				addedDirs[dir] = true
+			}
+		}
+	}
+
+	return out
+}
+
+// importsOf returns the import paths of the target, from its stored entry or its source.
+func (h Hierarchy) importsOf(target, content string) []string {
+	for _, f := range h.Files {
+		if f.Path == target && len(f.Imports) > 0 {
+			return f.Imports
+		}
+	}
+	if static, err := StaticDetails(target, content); err == nil {
+		return static.Imports
+	}
+	return nil
+}
+
+// importPathOf returns the module import path for a project-relative directory.
+func (h Hierarchy) importPathOf(dir string) string {
+	for _, p := range h.Packages {
+		if p.Path == dir && p.ImportPath != "" {
+			return p.ImportPath
+		}
+	}
+	if h.Module != nil {
+		return importPath(h.Module.Path, dir)
+	}
+	return ""
+}
+
+// bm25 scores each file's summary and export names against the query terms.
+func bm25(query []string, files []FileDetails) []float64 {
+	scores := make([]float64, len(files))
+	if len(query) == 0 || len(files) == 0 {
+		return scores
+	}
+
+	docs := make([]map[string]int, len(files))
+	lengths := make([]int, len(files))
+	docFreq := map[string]int{}
+	total := 0
+	for i, f := range files {
+		text := f.Summary
+		for _, e := range f.Exports {
+			text += " " + e.Name
+		}
+		terms := tokenize(text)
+		docs[i] = map[string]int{}
+		for _, t := range terms {
+			if docs[i][t] == 0 {
+				docFreq[t]++
+			}
+			docs[i][t]++
+		}
+		lengths[i] = len(terms)
+		total += len(terms)
+	}
+	avgLen := float64(total) / float64(len(files))
+	if avgLen == 0 {
+		return scores
+	}
+
+	// Each distinct query term counts once, so repetitive source does not dominate.
+	seen := map[string]bool{}
+	n := float64(len(files))
+	for _, q := range query {
+		if seen[q] || docFreq[q] == 0 {
+			continue
+		}
+		seen[q] = true
+		idf := math.Log(1 + (n-float64(docFreq[q])+0.5)/(float64(docFreq[q])+0.5))
+		for i := range files {
+			tf := float64(docs[i][q])
+			if tf == 0 {
+				continue
+			}
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(lengths[i])/avgLen)
+			scores[i] += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+	}
+	return scores
+}
+
+// tokenize lowercases text and splits it into words, also splitting camelCase identifiers.
+func tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		parts := splitCamel(word)
+		if len(parts) > 1 {
+			parts = append(parts, word) // Keep the whole identifier as well as its parts.
+		}
+		for _, p := range parts {
+			if len(p) >= minTokenLength {
+				terms = append(terms, strings.ToLower(p))
+			}
+		}
+	}
+	return terms
+}
+
+// splitCamel splits "GenerateContextBatch" into "Generate", "Context" and "Batch".
+func splitCamel(word string) []string {
+	var parts []string
+	start := 0
+	runes := []rune(word)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// approxTokens estimates how many tokens v takes once encoded as JSON.
+func approxTokens(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return tokens.Default.Estimate(string(data))
+}
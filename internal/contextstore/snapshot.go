@@ -0,0 +1,199 @@
+package contextstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the snapshot schema this build writes and the newest it reads.
+const SnapshotVersion = 1
+
+// Snapshot formats accepted by Encode and DecodeSnapshot.
+const (
+	FormatJSON  = "json"  // One JSON document.
+	FormatJSONL = "jsonl" // A meta record followed by one record per entry.
+	FormatTOON  = "toon"  // Compact TOON tables.
+)
+
+// SnapshotMeta describes where a snapshot came from.
+type SnapshotMeta struct {
+	Version     int       `json:"version"`      // Snapshot schema version.
+	Module      string    `json:"module"`       // Module path of the project the context describes.
+	ToolVersion string    `json:"tool_version"` // Version of autocommenter that wrote the snapshot.
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Snapshot is a portable copy of the stored context.
+type Snapshot struct {
+	Meta    SnapshotMeta `json:"meta"`
+	Context *Store       `json:"context"`
+}
+
+// snapshotRecord is one line of a JSONL snapshot.
+type snapshotRecord struct {
+	Kind    string          `json:"kind"` // meta, file, package, module or graph.
+	Meta    *SnapshotMeta   `json:"meta,omitempty"`
+	File    *FileDetails    `json:"file,omitempty"`
+	Package *PackageDetails `json:"package,omitempty"`
+	Module  *ModuleDetails  `json:"module,omitempty"`
+	Graph   *Graph          `json:"graph,omitempty"`
+}
+
+// NewSnapshot wraps the store with metadata for export.
+func NewSnapshot(s *Store, modulePath, toolVersion string) Snapshot {
+	return Snapshot{
+		Meta: SnapshotMeta{
+			Version:     SnapshotVersion,
+			Module:      modulePath,
+			ToolVersion: toolVersion,
+			CreatedAt:   time.Now().UTC(),
+		},
+		Context: s,
+	}
+}
+
+// FormatFromPath picks a snapshot format from a file extension, defaulting to JSON.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".toon":
+		return FormatTOON
+	}
+	return FormatJSON
+}
+
+// Validate reports whether the snapshot can be imported into the module at modulePath.
+func (s Snapshot) Validate(modulePath string) error {
+	switch {
+	case s.Meta.Version == 0:
+		return fmt.Errorf("snapshot has no schema version")
+	case s.Meta.Version > SnapshotVersion:
+		return fmt.Errorf("snapshot schema version %d is newer than supported version %d; upgrade autocommenter", s.Meta.Version, SnapshotVersion)
+	case s.Meta.Module != modulePath:
+		return fmt.Errorf("snapshot is for module %q, not %q", s.Meta.Module, modulePath)
+	case s.Context == nil:
+		return fmt.Errorf("snapshot has no context")
+	}
+	return nil
+}
+
+// Encode writes the snapshot to w in the given format.
+func (s Snapshot) Encode(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case FormatJSONL:
+		return s.encodeJSONL(w)
+	case FormatTOON:
+		return s.encodeTOON(w)
+	}
+	return fmt.Errorf("unknown snapshot format %q: supported formats are json, jsonl, toon", format)
+}
+
+// DecodeSnapshot reads a snapshot written by Encode in the given format.
+func DecodeSnapshot(data []byte, format string) (Snapshot, error) {
+	var s Snapshot
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.Unmarshal(data, &s)
+	case FormatJSONL:
+		s, err = decodeJSONL(data)
+	case FormatTOON:
+		s, err = decodeTOON(string(data))
+	default:
+		return s, fmt.Errorf("unknown snapshot format %q: supported formats are json, jsonl, toon", format)
+	}
+	if err != nil {
+		return s, fmt.Errorf("decode %s snapshot: %w", format, err)
+	}
+	if s.Context != nil {
+		s.Context.normalize()
+	}
+	return s, nil
+}
+
+// encodeJSONL writes the meta record, then the module, packages, files and graph in path order.
+func (s Snapshot) encodeJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w) // Encode terminates each record with a newline.
+	if err := enc.Encode(snapshotRecord{Kind: "meta", Meta: &s.Meta}); err != nil {
+		return err
+	}
+
+	h := s.Context.Hierarchy()
+	if h.Module != nil {
+		if err := enc.Encode(snapshotRecord{Kind: "module", Module: h.Module}); err != nil {
+			return err
+		}
+	}
+	for i := range h.Packages {
+		if err := enc.Encode(snapshotRecord{Kind: "package", Package: &h.Packages[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range h.Files {
+		if err := enc.Encode(snapshotRecord{Kind: "file", File: &h.Files[i]}); err != nil {
+			return err
+		}
+	}
+	if s.Context.Graph != nil {
+		return enc.Encode(snapshotRecord{Kind: "graph", Graph: s.Context.Graph})
+	}
+	return nil
+}
+
+// decodeJSONL reads records written by encodeJSONL. The meta record must come first.
+func decodeJSONL(data []byte) (Snapshot, error) {
+	s := Snapshot{Context: NewStore()}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Graph records can be long.
+
+	line := 0
+	first := true // No record read yet; blank lines do not count.
+	for sc.Scan() {
+		line++
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+
+		var r snapshotRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return s, fmt.Errorf("line %d: %w", line, err)
+		}
+		if first && r.Kind != "meta" {
+			return s, fmt.Errorf("line %d: expected meta record, got %q", line, r.Kind)
+		}
+		first = false
+
+		switch {
+		case r.Kind == "meta" && r.Meta != nil:
+			s.Meta = *r.Meta
+		case r.Kind == "file" && r.File != nil:
+			s.Context.Files[r.File.Path] = *r.File
+		case r.Kind == "package" && r.Package != nil:
+			s.Context.Packages[r.Package.Path] = *r.Package
+		case r.Kind == "module" && r.Module != nil:
+			s.Context.Module = r.Module
+		case r.Kind == "graph" && r.Graph != nil:
+			s.Context.Graph = r.Graph
+		default:
+			return s, fmt.Errorf("line %d: unknown or empty record %q", line, r.Kind)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return s, err
+	}
+	if first {
+		return s, fmt.Errorf("no meta record")
+	}
+	return s, nil
+}
@@ -0,0 +1,95 @@
+package contextstore
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/praneeth-ayla/autocommenter/internal/gosrc"
+)
+
+// camelCaseRe finds words that look like Go identifiers made of several
+// capitalized parts, such as "GenerateContextBatch".
+var camelCaseRe = regexp.MustCompile(`\b[A-Z][a-z0-9]+(?:[A-Z][a-z0-9]*)+\b`)
+
+// StaticDetails computes everything but the summary for a Go file from its source.
+// Non-Go files return an error, since there is nothing to extract.
+func StaticDetails(path, content string) (FileDetails, error) {
+	if filepath.Ext(path) != ".go" {
+		return FileDetails{}, fmt.Errorf("static extraction only supports Go files")
+	}
+
+	f, err := gosrc.Extract(path, []byte(content))
+	if err != nil {
+		return FileDetails{}, err
+	}
+
+	details := FileDetails{
+		Path:       path,
+		Name:       filepath.Base(path),
+		Package:    f.Package,
+		PackageDoc: f.Doc,
+		BuildTags:  f.BuildTags,
+		Imports:    f.Imports,
+		Exports:    make([]Export, 0, len(f.Exports)),
+	}
+	if details.Imports == nil {
+		details.Imports = []string{}
+	}
+	for _, e := range f.Exports {
+		details.Exports = append(details.Exports, Export{Name: e.Name, Kind: e.Kind, Signature: e.Signature})
+	}
+	return details, nil
+}
+
+// ApplyStatic combines a model-generated entry with statically extracted facts.
+// Only the summary and generation metadata are taken from the model. The returned
+// warnings describe where the model disagreed with the source: exports or imports
+// that differ from the static data, and identifiers mentioned in the summary that
+// do not appear anywhere in the file.
+func ApplyStatic(generated, static FileDetails, content string) (FileDetails, []string) {
+	var warnings []string
+
+	if len(generated.Exports) > 0 && !sameNames(exportNames(generated.Exports), exportNames(static.Exports)) {
+		warnings = append(warnings, "model exports differ from source; using static exports")
+	}
+	if len(generated.Imports) > 0 && !sameNames(generated.Imports, static.Imports) {
+		warnings = append(warnings, "model imports differ from source; using static imports")
+	}
+
+	if f, err := gosrc.Extract(static.Path, []byte(content)); err == nil {
+		for _, word := range camelCaseRe.FindAllString(generated.Summary, -1) {
+			if !slices.Contains(f.Identifiers, word) {
+				warnings = append(warnings, fmt.Sprintf("summary mentions %s, which is not in the file", word))
+			}
+		}
+	}
+
+	merged := static
+	merged.Path = generated.Path
+	merged.Summary = generated.Summary
+	merged.Hash = generated.Hash
+	merged.Model = generated.Model
+	merged.GeneratedAt = generated.GeneratedAt
+	return merged, warnings
+}
+
+func exportNames(exports []Export) []string {
+	names := make([]string, 0, len(exports))
+	for _, e := range exports {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+// sameNames compares two name lists ignoring order.
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
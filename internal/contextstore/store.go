@@ -2,36 +2,119 @@ package contextstore
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/praneeth-ayla/autocommenter/internal/config"
 	"github.com/praneeth-ayla/autocommenter/internal/scanner"
 )
 
-// Save stores the provided FileDetails map to a JSON file.
-func Save(all map[string]FileDetails) error {
+// ErrNotFound is returned by Load when no context has been generated yet.
+var ErrNotFound = errors.New("context file not found")
+
+// ErrSkipSave can be returned by an Update function to leave the context file untouched.
+var ErrSkipSave = errors.New("skip saving context")
+
+// Save replaces the context file with the provided context, holding the file lock
+// while writing. Use Update to change part of the context without losing
+// changes another process saved in the meantime.
+func Save(all *Store) error {
 	configPath, err := getConfigFilePath() // Determine the path for the configuration file.
 	if err != nil {
 		return err
 	}
-	fmt.Println(configPath) // Log the configuration file path.
 
 	err = ensureDir(filepath.Dir(configPath)) // Ensure the directory for the config file exists.
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(all, "", "  ") // Marshal the map into a pretty-printed JSON byte slice.
+	unlock, err := lockFile(configPath)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	return scanner.WriteFile(configPath, string(data)) // Write the JSON data to the file using scanner.WriteFile.
+	return write(configPath, all)
 }
 
-// Load retrieves FileDetails from the JSON configuration file.
-func Load() (map[string]FileDetails, error) {
+// Update loads the latest context under the file lock, applies fn and saves the
+// result before releasing the lock, so concurrent runs do not overwrite each
+// other. A missing context file starts out empty. If fn returns ErrSkipSave the
+// file is left as it is and Update returns nil.
+func Update(fn func(*Store) error) error {
+	configPath, err := getConfigFilePath()
+	if err != nil {
+		return err
+	}
+	if err := ensureDir(filepath.Dir(configPath)); err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, _, err := readFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		all = NewStore()
+	} else if err != nil {
+		return err
+	}
+
+	if err := fn(all); err != nil {
+		if errors.Is(err, ErrSkipSave) {
+			return nil
+		}
+		return err
+	}
+	return write(configPath, all)
+}
+
+// write encodes the context in the current schema and atomically replaces the file at path.
+// Callers hold the file lock.
+func write(path string, all *Store) error {
+	now := time.Now().UTC()
+	if all.createdAt.IsZero() {
+		all.createdAt = now
+	}
+	modulePath, _ := scanner.ModulePath(scanner.GetProjectRoot()) // Informational; empty outside Go modules.
+	env := envelope{
+		Version:   SchemaVersion,
+		Module:    modulePath,
+		CreatedAt: all.createdAt,
+		UpdatedAt: now,
+		Entries:   all,
+	}
+
+	data, err := json.MarshalIndent(env, "", "  ") // Marshal the envelope into a pretty-printed JSON byte slice.
+	if err != nil {
+		return err
+	}
+
+	return scanner.WriteFileAtomic(path, string(data))
+}
+
+// Load retrieves the stored context from the JSON configuration file.
+// Context stored in the old home-directory location is migrated on first use.
+func Load() (*Store, error) {
+	return load(true)
+}
+
+// LoadReadOnly is Load without writing anything: legacy context is read but not
+// migrated, and a file in an older schema is not upgraded. Commands that only
+// report, such as estimates, use it.
+func LoadReadOnly() (*Store, error) {
+	return load(false)
+}
+
+// load reads the context, migrating and upgrading it when write is set.
+func load(write bool) (*Store, error) {
 	configPath, err := getConfigFilePath() // Get the configuration file path.
 	if err != nil {
 		return nil, err
@@ -39,51 +122,132 @@ func Load() (map[string]FileDetails, error) {
 
 	// Check if the configuration file exists, return an error if it doesn't.
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("context file not found: %s", configPath)
+		var legacy *Store
+		if write {
+			legacy, err = migrateLegacy(configPath)
+		} else {
+			legacy, _, err = readLegacy()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "context migration skipped:", err)
+		}
+		if legacy == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, configPath)
+		}
+		return legacy, nil
 	}
 
-	data, err := os.ReadFile(configPath) // Read the content of the configuration file.
+	all, version, err := readFile(configPath)
 	if err != nil {
 		return nil, err
 	}
+	if version < SchemaVersion && write {
+		if err := upgradeInPlace(configPath, all, version); err != nil {
+			fmt.Fprintln(os.Stderr, "context schema upgrade not saved:", err)
+		}
+	}
+	return all, nil
+}
 
-	var all map[string]FileDetails
-	err = json.Unmarshal(data, &all) // Unmarshal the JSON data into the 'all' map.
+// readFile decodes a context file of any supported schema version and returns
+// the version it was written in.
+func readFile(path string) (*Store, int, error) {
+	data, err := os.ReadFile(path) // Read the content of the configuration file.
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return all, nil
+	env, version, err := decode(data)
+	if err != nil {
+		return nil, version, fmt.Errorf("%s: %w", path, err)
+	}
+	return env.Entries, version, nil
 }
 
-// getConfigFilePath determines the full path to the context JSON file.
+// upgradeInPlace rewrites a context file of an older schema in the current one,
+// keeping the original next to it as <file>.v<version>.bak.
+func upgradeInPlace(path string, all *Store, version int) error {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(backup, original, 0644); err != nil {
+		return err
+	}
+	if err := Save(all); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Upgraded context file from schema %d to %d (previous copy: %s)\n", version, SchemaVersion, backup)
+	return nil
+}
+
+// getConfigFilePath determines the full path to the context JSON file inside the project.
 func getConfigFilePath() (string, error) {
-	home, err := os.UserHomeDir() // Get the user's home directory.
+	projectRoot := scanner.GetProjectRoot() // Get the project's root directory.
+
+	cfg, err := config.LoadProject(projectRoot)
 	if err != nil {
 		return "", err
 	}
+	return cfg.Context.ContextFile(projectRoot), nil
+}
 
-	projectRoot := scanner.GetProjectRoot()           // Get the project's root directory.
-	goModPath := filepath.Join(projectRoot, "go.mod") // Construct the path to the go.mod file.
-
-	data, err := os.ReadFile(goModPath) // Read the content of the go.mod file.
+// legacyFilePath returns where context was stored before it moved into the project:
+// ~/autocommenter/<last element of the module path>.json.
+func legacyFilePath(projectRoot string) (string, error) {
+	home, err := os.UserHomeDir() // Get the user's home directory.
 	if err != nil {
 		return "", err
 	}
 
-	var moduleName string
-	// Parse the go.mod file to extract the module name.
-	_, err = fmt.Sscanf(string(data), "module %s", &moduleName)
+	modulePath, err := scanner.ModulePath(projectRoot)
 	if err != nil {
-		return "", fmt.Errorf("failed to read module name")
+		return "", err
 	}
 
-	projectName := filepath.Base(moduleName) // Extract the project name from the module name.
+	return filepath.Join(home, "autocommenter", filepath.Base(modulePath)+".json"), nil
+}
 
-	fileName := projectName + ".json" // Construct the filename for the context file.
+// migrateLegacy copies context from the legacy location to configPath. It returns
+// nil without error when there is nothing to migrate.
+func migrateLegacy(configPath string) (*Store, error) {
+	all, legacy, err := readLegacy()
+	if all == nil || err != nil {
+		return nil, err
+	}
+	if err := Save(all); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Migrated context from %s to %s (the old file can be removed)\n", legacy, configPath)
+	return all, nil
+}
 
-	// Combine home directory, a fixed directory name, and the generated filename.
-	return filepath.Join(home, "autocommenter", fileName), nil
+// readLegacy reads context from the legacy location and returns it with its
+// path. It returns nil without error when there is none. Because legacy files
+// were keyed only by the last module path element, a file is only adopted when
+// at least one of its entries refers to a file that exists in this project.
+func readLegacy() (*Store, string, error) {
+	projectRoot := scanner.GetProjectRoot()
+	legacy, err := legacyFilePath(projectRoot)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := os.Stat(legacy); os.IsNotExist(err) {
+		return nil, "", nil
+	}
+
+	all, _, err := readFile(legacy)
+	if err != nil {
+		return nil, "", fmt.Errorf("read %s: %w", legacy, err)
+	}
+
+	for path := range all.Files {
+		if _, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(path))); err == nil {
+			return all, legacy, nil
+		}
+	}
+	return nil, "", fmt.Errorf("%s does not describe this project", legacy)
 }
 
 // ensureDir creates a directory and any necessary parent directories if they don't exist.
@@ -0,0 +1,286 @@
+package contextstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alpkeskin/gotoon"
+)
+
+// TOON snapshots are a set of top-level tables, one per kind of entry. Table
+// cells must be primitives, so list-valued fields are stored as JSON text.
+// gotoon only encodes; decodeTOON reads back exactly the shape written here.
+
+// encodeTOON writes the snapshot as TOON tables.
+func (s Snapshot) encodeTOON(w io.Writer) error {
+	h := s.Context.Hierarchy()
+	doc := map[string]any{}
+	var err error
+
+	if doc["meta"], err = toonRows([]SnapshotMeta{s.Meta}, "version"); err != nil {
+		return err
+	}
+	if h.Module != nil {
+		if doc["module"], err = toonRows([]ModuleDetails{*h.Module}); err != nil {
+			return err
+		}
+	}
+	if doc["packages"], err = toonRows(h.Packages, "files"); err != nil {
+		return err
+	}
+	if doc["files"], err = toonRows(h.Files, "exports", "imports"); err != nil {
+		return err
+	}
+	if g := s.Context.Graph; g != nil {
+		if doc["graph_packages"], err = toonRows(g.Packages); err != nil {
+			return err
+		}
+		if doc["graph_edges"], err = toonRows(g.Edges); err != nil {
+			return err
+		}
+	}
+
+	encoded, err := gotoon.Encode(doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, encoded+"\n")
+	return err
+}
+
+// decodeTOON reads a snapshot written by encodeTOON.
+func decodeTOON(text string) (Snapshot, error) {
+	s := Snapshot{Context: NewStore()}
+	tables, err := parseTOONTables(text)
+	if err != nil {
+		return s, err
+	}
+
+	metas, err := fromToonRows[SnapshotMeta](tables["meta"], "version")
+	if err != nil {
+		return s, fmt.Errorf("meta: %w", err)
+	}
+	if len(metas) != 1 {
+		return s, fmt.Errorf("expected one meta row, found %d", len(metas))
+	}
+	s.Meta = metas[0]
+
+	modules, err := fromToonRows[ModuleDetails](tables["module"])
+	if err != nil {
+		return s, fmt.Errorf("module: %w", err)
+	}
+	if len(modules) > 0 {
+		s.Context.Module = &modules[0]
+	}
+
+	packages, err := fromToonRows[PackageDetails](tables["packages"], "files")
+	if err != nil {
+		return s, fmt.Errorf("packages: %w", err)
+	}
+	for _, p := range packages {
+		s.Context.Packages[p.Path] = p
+	}
+
+	files, err := fromToonRows[FileDetails](tables["files"], "exports", "imports")
+	if err != nil {
+		return s, fmt.Errorf("files: %w", err)
+	}
+	for _, f := range files {
+		s.Context.Files[f.Path] = f
+	}
+
+	if _, ok := tables["graph_packages"]; ok {
+		g := &Graph{Module: s.Meta.Module} // The graph is always built for the snapshot's module.
+		if g.Packages, err = fromToonRows[GraphNode](tables["graph_packages"]); err != nil {
+			return s, fmt.Errorf("graph packages: %w", err)
+		}
+		if g.Edges, err = fromToonRows[GraphEdge](tables["graph_edges"]); err != nil {
+			return s, fmt.Errorf("graph edges: %w", err)
+		}
+		s.Context.Graph = g
+	}
+	return s, nil
+}
+
+// toonRows flattens items into rows of strings for a TOON table. Fields named in
+// jsonCols hold their JSON encoding. Every row gets every column, since gotoon
+// only writes a table when all rows share the same keys.
+func toonRows[T any](items []T, jsonCols ...string) ([]map[string]any, error) {
+	rows := make([]map[string]any, 0, len(items))
+	columns := map[string]bool{}
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(fields))
+		for key, raw := range fields {
+			columns[key] = true
+			if slices.Contains(jsonCols, key) {
+				row[key] = string(raw)
+				continue
+			}
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("field %s is not a string", key)
+			}
+			row[key] = value
+		}
+		rows = append(rows, row)
+	}
+
+	for _, row := range rows {
+		for key := range columns {
+			if _, ok := row[key]; !ok {
+				row[key] = "" // Optional field left out of this row's JSON.
+			}
+		}
+	}
+	return rows, nil
+}
+
+// fromToonRows rebuilds items from table rows produced by toonRows.
+func fromToonRows[T any](rows []map[string]string, jsonCols ...string) ([]T, error) {
+	items := make([]T, 0, len(rows))
+	for i, row := range rows {
+		fields := make(map[string]json.RawMessage, len(row))
+		for key, value := range row {
+			if value == "" {
+				continue // Absent optional field; the zero value is right.
+			}
+			if slices.Contains(jsonCols, key) {
+				fields[key] = json.RawMessage(value)
+				continue
+			}
+			fields[key], _ = json.Marshal(value)
+		}
+
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		var item T
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// toonTableHeader matches a table header such as "files[2]{path,summary}:" or "files[0]:".
+var toonTableHeader = regexp.MustCompile(`^([A-Za-z_][\w.]*)\[(\d+)\](?:\{([^}]*)\})?:$`)
+
+// parseTOONTables reads top-level TOON tables with comma-delimited rows into
+// rows of strings keyed by table name. Unquoted null reads as an empty string.
+func parseTOONTables(text string) (map[string][]map[string]string, error) {
+	tables := map[string][]map[string]string{}
+	lines := strings.Split(text, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		m := toonTableHeader.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected a table header, got %q", i+1, line)
+		}
+		name := m[1]
+		count, _ := strconv.Atoi(m[2])
+		var fields []string
+		if m[3] != "" {
+			fields = strings.Split(m[3], ",")
+		}
+
+		rows := make([]map[string]string, 0, count)
+		for r := 0; r < count; r++ {
+			i++
+			if i >= len(lines) || !strings.HasPrefix(lines[i], " ") {
+				return nil, fmt.Errorf("table %s: expected %d rows, found %d", name, count, r)
+			}
+			cells, err := splitTOONRow(strings.TrimSpace(lines[i]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if len(cells) != len(fields) {
+				return nil, fmt.Errorf("line %d: expected %d cells, found %d", i+1, len(fields), len(cells))
+			}
+
+			row := make(map[string]string, len(fields))
+			for j, f := range fields {
+				row[f] = cells[j]
+			}
+			rows = append(rows, row)
+		}
+		tables[name] = rows
+	}
+	return tables, nil
+}
+
+// splitTOONRow splits a comma-delimited row, unquoting and unescaping quoted cells.
+func splitTOONRow(row string) ([]string, error) {
+	var cells []string
+	for {
+		var cell string
+		if strings.HasPrefix(row, `"`) {
+			var sb strings.Builder
+			j, closed := 1, false
+			for j < len(row) {
+				c := row[j]
+				if c == '\\' && j+1 < len(row) {
+					switch row[j+1] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					default:
+						sb.WriteByte(row[j+1]) // Backslash and double quote.
+					}
+					j += 2
+					continue
+				}
+				j++
+				if c == '"' {
+					closed = true
+					break
+				}
+				sb.WriteByte(c)
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quoted value")
+			}
+			cell, row = sb.String(), row[j:]
+			if row != "" && row[0] != ',' {
+				return nil, fmt.Errorf("unexpected text after quoted value: %q", row)
+			}
+		} else {
+			end := strings.IndexByte(row, ',')
+			if end < 0 {
+				end = len(row)
+			}
+			cell, row = row[:end], row[end:]
+			if cell == "null" {
+				cell = ""
+			}
+		}
+
+		cells = append(cells, cell)
+		if row == "" {
+			return cells, nil
+		}
+		row = row[1:] // Skip the delimiter.
+	}
+}
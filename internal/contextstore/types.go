@@ -1,9 +1,75 @@
 package contextstore
 
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
 type FileDetails struct {
-	Path    string   `json:"path"`      // Path to the file.
-	Name    string   `json:"file_name"` // Name of the file.
-	Exports []string `json:"exports"`   // List of exported identifiers from the file.
-	Imports []string `json:"imports"`   // List of imported packages.
-	Summary string   `json:"summary"`   // A brief summary of the file's purpose.
+	Path        string    `json:"path"`                  // Path to the file.
+	Name        string    `json:"file_name"`             // Name of the file.
+	Package     string    `json:"package,omitempty"`     // Package name from the package clause.
+	PackageDoc  string    `json:"package_doc,omitempty"` // Package doc comment, if this file carries it.
+	BuildTags   string    `json:"build_tags,omitempty"`  // Build constraint expression, if any.
+	Exports     []Export  `json:"exports"`               // Exported identifiers declared in the file.
+	Imports     []string  `json:"imports"`               // List of imported packages.
+	Summary     string    `json:"summary"`               // A brief summary of the file's purpose.
+	Hash        string    `json:"hash,omitempty"`        // Content hash of the file the entry was generated from.
+	Model       string    `json:"model,omitempty"`       // Model that produced the entry.
+	GeneratedAt time.Time `json:"generated_at,omitzero"` // When the entry was generated.
+}
+
+// Export describes an exported identifier and, when known, its kind and signature.
+type Export struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`      // func, method, type, var or const.
+	Signature string `json:"signature,omitempty"` // Declaration without body, e.g. "func Load() (*Config, error)".
+}
+
+// UnmarshalJSON accepts both the object form and a bare name, which older
+// context files and model output use.
+func (e *Export) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*e = Export{Name: name}
+		return nil
+	}
+
+	type plain Export // Avoid recursing into this method.
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*e = Export(p)
+	return nil
+}
+
+// MergeParts combines the entries generated for the parts of a split file into
+// one: summaries are joined in order and exports and imports are merged.
+func MergeParts(parts []FileDetails) FileDetails {
+	merged := parts[0]
+	merged.Exports, merged.Imports = nil, nil
+	var summaries []string
+	for _, p := range parts {
+		if s := strings.TrimSpace(p.Summary); s != "" {
+			summaries = append(summaries, s)
+		}
+		for _, e := range p.Exports {
+			if !slices.ContainsFunc(merged.Exports, func(m Export) bool { return m.Name == e.Name }) {
+				merged.Exports = append(merged.Exports, e)
+			}
+		}
+		for _, imp := range p.Imports {
+			if !slices.Contains(merged.Imports, imp) {
+				merged.Imports = append(merged.Imports, imp)
+			}
+		}
+		if merged.Package == "" {
+			merged.Package = p.Package
+		}
+	}
+	merged.Summary = strings.Join(summaries, " ")
+	return merged
 }
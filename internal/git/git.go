@@ -0,0 +1,216 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DiffOptions selects which changes Diff reports.
+type DiffOptions struct {
+	Since  string // Compare against the merge base of this ref and HEAD.
+	Staged bool   // Only consider changes in the index.
+}
+
+// Enabled reports whether any restriction was requested.
+func (o DiffOptions) Enabled() bool {
+	return o.Since != "" || o.Staged
+}
+
+// LineRange is an inclusive range of line numbers in the new version of a file.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// FileChange describes the added or modified lines of one file.
+type FileChange struct {
+	Path  string      // Path relative to the directory Diff was run in, slash separated.
+	Lines []LineRange // Changed line ranges in the new version of the file.
+}
+
+// Run executes git with args in dir and returns its trimmed standard output.
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// Diff lists files added, copied, modified or renamed relative to the requested
+// base, together with the line ranges touched in each file. Paths are relative
+// to dir, and files outside dir are left out.
+func Diff(dir string, opts DiffOptions) ([]FileChange, error) {
+	args := []string{"-c", "core.quotepath=off", "diff", "--relative", "--no-prefix", "--no-color", "--no-ext-diff", "-U0", "--diff-filter=ACMR"}
+	if opts.Staged {
+		args = append(args, "--cached")
+	}
+	if opts.Since != "" {
+		base, err := Run(dir, "merge-base", opts.Since, "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", opts.Since, err)
+		}
+		args = append(args, base)
+	}
+
+	out, err := Run(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiff(out), nil
+}
+
+// parseDiff extracts file paths and new-side hunk ranges from unified diff output.
+func parseDiff(out string) []FileChange {
+	var changes []FileChange
+	var current *FileChange
+
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			changes = append(changes, FileChange{Path: unquotePath(strings.TrimPrefix(line, "+++ "))})
+			current = &changes[len(changes)-1]
+		case strings.HasPrefix(line, "@@ ") && current != nil:
+			if r, ok := parseHunk(line); ok {
+				current.Lines = append(current.Lines, r)
+			}
+		}
+	}
+	return changes
+}
+
+// unquotePath decodes a path git quoted because it holds control characters,
+// quotes or backslashes (or, without core.quotepath=off, non-ASCII bytes). Git
+// uses C-style escapes, which Go string literals share.
+func unquotePath(path string) string {
+	if !strings.HasPrefix(path, `"`) {
+		return path
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return strings.Trim(path, `"`)
+}
+
+// parseHunk reads the "+start,count" part of a hunk header such as "@@ -3,2 +4,5 @@".
+func parseHunk(header string) (LineRange, bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return LineRange{}, false
+	}
+
+	spec := strings.TrimPrefix(fields[2], "+")
+	startStr, countStr, hasCount := strings.Cut(spec, ",")
+
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return LineRange{}, false
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return LineRange{}, false
+		}
+	}
+	if count == 0 {
+		return LineRange{}, false // Pure deletion, nothing new to document.
+	}
+	return LineRange{Start: start, End: start + count - 1}, true
+}
+
+// HooksDir returns the absolute directory git runs hooks from, honouring core.hooksPath.
+func HooksDir(dir string) (string, error) {
+	out, err := Run(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out) // git answers relative to the working directory.
+	}
+	return out, nil
+}
+
+// Add stages the given paths.
+func Add(dir string, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	_, err := Run(dir, append([]string{"add", "--"}, paths...)...)
+	return err
+}
+
+// IsClean reports whether tracked files have no staged or unstaged changes.
+// Untracked files are not considered.
+func IsClean(dir string) (bool, error) {
+	out, err := Run(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "", nil
+}
+
+// CreateBranch creates a branch at HEAD and switches to it.
+func CreateBranch(dir, name string) error {
+	_, err := Run(dir, "checkout", "-b", name)
+	return err
+}
+
+// StagedPaths returns which of paths differ between the index and HEAD.
+func StagedPaths(dir string, paths ...string) ([]string, error) {
+	out, err := Run(dir, append([]string{"diff", "--cached", "--relative", "--name-only", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// UnstagedPaths returns which of paths have changes in the working tree that are not staged.
+func UnstagedPaths(dir string, paths ...string) ([]string, error) {
+	out, err := Run(dir, append([]string{"diff", "--relative", "--name-only", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// splitNUL splits the NUL-terminated paths git prints with -z, which are never quoted.
+func splitNUL(out string) []string {
+	out = strings.TrimSuffix(out, "\x00")
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\x00")
+}
+
+// ShowStaged returns the staged content of path, relative to dir.
+func ShowStaged(dir, path string) ([]byte, error) {
+	cmd := exec.Command("git", "show", ":./"+filepath.ToSlash(path))
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show :%s: %s", path, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// Commit commits only the given paths, leaving anything else in the index untouched.
+func Commit(dir, message string, paths ...string) error {
+	_, err := Run(dir, append([]string{"commit", "-m", message, "--"}, paths...)...)
+	return err
+}
@@ -0,0 +1,146 @@
+package gosrc
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// Decl describes a top-level declaration in a Go source file.
+type Decl struct {
+	Name     string // Identifier, or "Recv.Method" for methods.
+	Kind     string // One of func, method, type, var or const.
+	Line     int    // First line of the declaration.
+	EndLine  int    // Last line of the declaration.
+	Exported bool
+	HasDoc   bool
+}
+
+// Overlaps reports whether the declaration spans any line in [start, end].
+func (d Decl) Overlaps(start, end int) bool {
+	return d.Line <= end && start <= d.EndLine
+}
+
+// Decls parses src and returns its top-level declarations in source order.
+func Decls(filename string, src []byte) ([]Decl, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var decls []Decl
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			decls = append(decls, funcDecl(fset, d))
+		case *ast.GenDecl:
+			decls = append(decls, genDecls(fset, d)...)
+		}
+	}
+	return decls, nil
+}
+
+// DeclStarts returns the first line of each top-level declaration in src, counting
+// its doc comment, in source order. These are the places a file can be cut without
+// splitting a declaration.
+func DeclStarts(filename string, src []byte) ([]int, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var starts []int
+	for _, decl := range file.Decls {
+		pos := decl.Pos()
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil {
+				pos = d.Doc.Pos()
+			}
+		case *ast.GenDecl:
+			if d.Doc != nil {
+				pos = d.Doc.Pos()
+			}
+		}
+		starts = append(starts, fset.Position(pos).Line)
+	}
+	return starts, nil
+}
+
+// MissingDocs returns the exported declarations that have no doc comment.
+func MissingDocs(decls []Decl) []Decl {
+	var missing []Decl
+	for _, d := range decls {
+		if d.Exported && !d.HasDoc {
+			missing = append(missing, d)
+		}
+	}
+	return missing
+}
+
+func funcDecl(fset *token.FileSet, d *ast.FuncDecl) Decl {
+	out := Decl{
+		Name:     d.Name.Name,
+		Kind:     "func",
+		Line:     fset.Position(d.Pos()).Line,
+		EndLine:  fset.Position(d.End()).Line,
+		Exported: d.Name.IsExported(),
+		HasDoc:   d.Doc != nil,
+	}
+
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		recv := receiverName(d.Recv.List[0].Type)
+		out.Name = recv + "." + d.Name.Name
+		out.Kind = "method"
+		out.Exported = out.Exported && ast.IsExported(recv) // Methods on unexported types are not API.
+	}
+	return out
+}
+
+// genDecls expands a type, var or const block into one Decl per spec.
+// A doc comment on the whole block counts for every spec inside it.
+func genDecls(fset *token.FileSet, d *ast.GenDecl) []Decl {
+	var out []Decl
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			out = append(out, Decl{
+				Name:     s.Name.Name,
+				Kind:     "type",
+				Line:     fset.Position(s.Pos()).Line,
+				EndLine:  fset.Position(s.End()).Line,
+				Exported: s.Name.IsExported(),
+				HasDoc:   d.Doc != nil || s.Doc != nil,
+			})
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				out = append(out, Decl{
+					Name:     name.Name,
+					Kind:     d.Tok.String(),
+					Line:     fset.Position(s.Pos()).Line,
+					EndLine:  fset.Position(s.End()).Line,
+					Exported: name.IsExported(),
+					HasDoc:   d.Doc != nil || s.Doc != nil,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// receiverName returns the base type name of a method receiver, without pointers or type parameters.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
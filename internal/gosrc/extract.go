@@ -0,0 +1,190 @@
+package gosrc
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// File holds the facts about a Go source file that can be computed without a model.
+type File struct {
+	Package     string
+	Doc         string   // Package doc comment, if this file carries it.
+	BuildTags   string   // Build constraint expression from //go:build, if any.
+	Imports     []string // Import paths in source order.
+	Exports     []Export
+	Identifiers []string // Every identifier used in the file, for cross-checking summaries.
+}
+
+// Export describes an exported top-level identifier.
+type Export struct {
+	Name      string
+	Kind      string // func, method, type, var or const.
+	Signature string
+}
+
+// Extract parses src and collects its package clause, build constraints, imports and exports.
+func Extract(filename string, src []byte) (File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return File{}, err
+	}
+
+	out := File{
+		Package:   file.Name.Name,
+		BuildTags: buildConstraint(file),
+	}
+	if file.Doc != nil {
+		out.Doc = strings.TrimSpace(file.Doc.Text())
+	}
+
+	for _, imp := range file.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+			out.Imports = append(out.Imports, path)
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if e, ok := funcExport(fset, d); ok {
+				out.Exports = append(out.Exports, e)
+			}
+		case *ast.GenDecl:
+			out.Exports = append(out.Exports, genExports(fset, d)...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && !seen[id.Name] {
+			seen[id.Name] = true
+			out.Identifiers = append(out.Identifiers, id.Name)
+		}
+		return true
+	})
+
+	return out, nil
+}
+
+// buildConstraint returns the //go:build expression that precedes the package clause.
+func buildConstraint(file *ast.File) string {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if expr, ok := strings.CutPrefix(c.Text, "//go:build "); ok {
+				return strings.TrimSpace(expr)
+			}
+		}
+	}
+	return ""
+}
+
+func funcExport(fset *token.FileSet, d *ast.FuncDecl) (Export, bool) {
+	if !d.Name.IsExported() {
+		return Export{}, false
+	}
+
+	e := Export{Name: d.Name.Name, Kind: "func"}
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		recv := receiverName(d.Recv.List[0].Type)
+		if !ast.IsExported(recv) {
+			return Export{}, false // Methods on unexported types are not part of the API.
+		}
+		e.Name = recv + "." + d.Name.Name
+		e.Kind = "method"
+	}
+
+	sig := *d
+	sig.Doc = nil
+	sig.Body = nil // Print only the signature.
+	e.Signature = render(fset, &sig)
+	return e, true
+}
+
+func genExports(fset *token.FileSet, d *ast.GenDecl) []Export {
+	var out []Export
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if s.Name.IsExported() {
+				out = append(out, Export{Name: s.Name.Name, Kind: "type", Signature: typeSignature(fset, s)})
+			}
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				if !name.IsExported() {
+					continue
+				}
+				sig := d.Tok.String() + " " + name.Name
+				if s.Type != nil {
+					sig += " " + render(fset, s.Type)
+				}
+				out = append(out, Export{Name: name.Name, Kind: d.Tok.String(), Signature: sig})
+			}
+		}
+	}
+	return out
+}
+
+// typeSignature abbreviates struct and interface bodies so signatures stay one line.
+func typeSignature(fset *token.FileSet, s *ast.TypeSpec) string {
+	head := "type " + s.Name.Name
+	if s.TypeParams != nil {
+		head += "[" + typeParams(fset, s.TypeParams) + "]"
+	}
+	if s.Assign.IsValid() {
+		head += " ="
+	}
+
+	switch s.Type.(type) {
+	case *ast.StructType:
+		return head + " struct"
+	case *ast.InterfaceType:
+		return head + " interface"
+	}
+	return head + " " + render(fset, s.Type)
+}
+
+// typeParams prints a type parameter list without brackets, e.g. "K comparable, V any".
+func typeParams(fset *token.FileSet, list *ast.FieldList) string {
+	var parts []string
+	for _, f := range list.List {
+		var names []string
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		parts = append(parts, strings.Join(names, ", ")+" "+render(fset, f.Type))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// render prints a node on a single line.
+func render(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// Imports parses only the package clause and import block of src.
+func Imports(filename string, src []byte) (pkg string, imports []string, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly|parser.SkipObjectResolution)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, imp := range file.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+			imports = append(imports, path)
+		}
+	}
+	return file.Name.Name, imports, nil
+}
@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry records one request that was sent, or refused, and what it carried.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Project  string    `json:"project"`
+	Command  string    `json:"command"`
+	Provider string    `json:"provider"`
+	Local    bool      `json:"local"` // Whether the provider runs on this machine.
+	Model    string    `json:"model"`
+	Task     string    `json:"task"`               // What the request was for, e.g. "comments".
+	Files    []Content `json:"files,omitempty"`    // Content sent as is, after secret redaction.
+	Context  []string  `json:"context,omitempty"`  // Files and packages whose stored summaries were included.
+	Withheld []string  `json:"withheld,omitempty"` // Paths left out of the request by the policy.
+	Refused  string    `json:"refused,omitempty"`  // Why the request was not sent at all.
+}
+
+// Content identifies what was sent of one file without storing it.
+type Content struct {
+	Path   string `json:"path"`
+	Bytes  int    `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// Describe returns the Content record of text sent for path.
+func Describe(path, text string) Content {
+	sum := sha256.Sum256([]byte(text))
+	return Content{Path: path, Bytes: len(text), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// AuditLog appends an Entry per request to a JSON Lines file. It is safe for concurrent use.
+type AuditLog struct {
+	mu       sync.Mutex
+	path     string
+	project  string
+	command  string
+	provider string
+}
+
+// OpenAudit returns the audit log at path for requests that command makes to
+// provider on behalf of project.
+func OpenAudit(path, project, command, provider string) *AuditLog {
+	return &AuditLog{path: path, project: project, command: command, provider: provider}
+}
+
+// Append writes e, filling in the time, project, command and provider.
+func (a *AuditLog) Append(e Entry) error {
+	e.Time = time.Now().UTC()
+	e.Project = a.project
+	e.Command = a.command
+	e.Provider = a.provider
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("audit log: %w", err)
+	}
+	return f.Close()
+}
+
+type auditKey struct{}
+
+// pending is the audit entry of a request that has not been sent yet.
+type pending struct {
+	log   *AuditLog
+	entry Entry
+}
+
+// WithAudit returns a context whose requests are recorded in log as e once
+// they are actually sent, so answers from the response cache are not.
+func WithAudit(ctx context.Context, log *AuditLog, e Entry) context.Context {
+	return context.WithValue(ctx, auditKey{}, &pending{log: log, entry: e})
+}
+
+// Sending records a request to model in the audit log carried by ctx, if any.
+// Providers call it right before each request leaves the machine, and must not
+// send a request it returns an error for.
+func Sending(ctx context.Context, model string) error {
+	p, _ := ctx.Value(auditKey{}).(*pending)
+	if p == nil {
+		return nil
+	}
+	e := p.entry
+	e.Model = model
+	return p.log.Append(e)
+}
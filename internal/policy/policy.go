@@ -0,0 +1,191 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/praneeth-ayla/autocommenter/internal/scanner"
+)
+
+// FileName is the egress policy file looked up in the project root. It is kept
+// apart from .autocommenter.json so it can have its own owners.
+const FileName = ".autocommenter-policy.json"
+
+// Level says where a file's content may be sent.
+type Level int
+
+const (
+	Allow     Level = iota // Any provider.
+	LocalOnly              // Only providers running on this machine.
+	Deny                   // No provider.
+)
+
+func (l Level) String() string {
+	switch l {
+	case LocalOnly:
+		return "local-only"
+	case Deny:
+		return "deny"
+	}
+	return "allow"
+}
+
+// ErrWithheld is returned instead of sending content the policy keeps from a provider.
+var ErrWithheld = errors.New("withheld by egress policy")
+
+// Policy assigns files a Level by path glob (see scanner.MatchGlob). When a file
+// matches several lists the most restrictive wins; files matching none get the
+// default, so "allow" globs only matter when the default is stricter.
+type Policy struct {
+	Deny      []string `json:"deny,omitempty"`       // Never sent to any provider.
+	LocalOnly []string `json:"local_only,omitempty"` // Sent only to local providers.
+	Allow     []string `json:"allow,omitempty"`      // Sent to any provider.
+	Default   string   `json:"default,omitempty"`    // Level of unmatched files: "allow" (default), "local-only" or "deny".
+	AuditLog  string   `json:"audit_log,omitempty"`  // Audit log path, relative to the project root unless absolute.
+
+	root string
+	def  Level
+}
+
+// Load reads the policy of the project rooted at root. Without a policy file
+// every file is allowed everywhere.
+func Load(root string) (*Policy, error) {
+	p := &Policy{}
+	data, err := os.ReadFile(filepath.Join(root, FileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", FileName, err)
+		}
+	}
+
+	switch p.Default {
+	case "", "allow":
+		p.def = Allow
+	case "local-only":
+		p.def = LocalOnly
+	case "deny":
+		p.def = Deny
+	default:
+		return nil, fmt.Errorf("%s: unknown default %q (want allow, local-only or deny)", FileName, p.Default)
+	}
+	p.root = root
+	return p, nil
+}
+
+// Level returns the level of a path, absolute or relative to the project root.
+func (p *Policy) Level(path string) Level {
+	rel := p.Rel(path)
+	switch {
+	case matchAny(p.Deny, rel):
+		return Deny
+	case matchAny(p.LocalOnly, rel):
+		return LocalOnly
+	case matchAny(p.Allow, rel):
+		return Allow
+	}
+	return p.def
+}
+
+// AuditPath returns the audit log configured by the policy, or "" for the default.
+func (p *Policy) AuditPath() string {
+	if p.AuditLog == "" || filepath.IsAbs(p.AuditLog) {
+		return p.AuditLog
+	}
+	return filepath.Join(p.root, filepath.FromSlash(p.AuditLog))
+}
+
+// Gate returns the gate for a provider, local or hosted.
+func (p *Policy) Gate(local bool) *Gate {
+	return &Gate{policy: p, local: local}
+}
+
+// Rel makes path slash-separated and relative to the project root.
+func (p *Policy) Rel(path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(p.root, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// matchAny reports whether rel matches one of patterns.
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if scanner.MatchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Gate applies a policy to one provider. A nil *Gate allows everything.
+type Gate struct {
+	policy *Policy
+	local  bool
+}
+
+// Local reports whether the gate's provider runs on this machine.
+func (g *Gate) Local() bool {
+	return g != nil && g.local
+}
+
+// Allows reports whether path's content may be sent to the gate's provider.
+func (g *Gate) Allows(path string) bool {
+	return g.Check(path) == nil
+}
+
+// Check returns an error wrapping ErrWithheld if path's content may not be sent
+// to the gate's provider.
+func (g *Gate) Check(path string) error {
+	if g == nil {
+		return nil
+	}
+	switch level := g.policy.Level(path); {
+	case level == Deny, level == LocalOnly && !g.local:
+		return fmt.Errorf("%w: %s is %s", ErrWithheld, g.policy.Rel(path), level)
+	}
+	return nil
+}
+
+// String describes the gate's provider for messages, e.g. "hosted provider".
+func (g *Gate) String() string {
+	if g.Local() {
+		return "local provider"
+	}
+	return "hosted provider"
+}
+
+type gateKey struct{}
+type sourceKey struct{}
+
+// WithGate returns a context whose requests may only include what g allows.
+// Providers consult it for content they gather themselves, such as a file tree.
+func WithGate(ctx context.Context, g *Gate) context.Context {
+	return context.WithValue(ctx, gateKey{}, g)
+}
+
+// GateFromContext returns the gate carried by ctx, or nil, which allows everything.
+func GateFromContext(ctx context.Context) *Gate {
+	g, _ := ctx.Value(gateKey{}).(*Gate)
+	return g
+}
+
+// WithSource returns a context whose requests send the content of the file at
+// path, such as the source file to comment or the existing README.
+func WithSource(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, path)
+}
+
+// SourceFromContext returns the path set by WithSource, and whether one was set.
+func SourceFromContext(ctx context.Context) (string, bool) {
+	path, ok := ctx.Value(sourceKey{}).(string)
+	return path, ok && path != ""
+}
@@ -0,0 +1,288 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLiveTasks is how many running tasks the live block lists before summarizing the rest.
+const maxLiveTasks = 8
+
+var spinner = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Renderer shows the progress of generation tasks that may run concurrently. On
+// a terminal it keeps a live block at the bottom of the output with every running
+// task, the tokens received so far and the elapsed time; finished tasks scroll
+// up above it. Elsewhere it prints one plain line when a task starts and ends.
+type Renderer struct {
+	mu      sync.Mutex
+	out     io.Writer
+	live    bool
+	width   int
+	title   string
+	total   int // Expected number of tasks, or 0 when unknown.
+	started time.Time
+	active  []*Task
+	done    int
+	failed  int
+	tokens  int // Tokens received by finished tasks.
+	drawn   int // Lines of the live block currently on screen.
+	frame   int
+	quit    chan struct{}
+	stopped chan struct{}
+}
+
+// Task is one request tracked by a Renderer. A nil *Task ignores every call, so
+// code reporting progress does not need to check whether anyone is listening.
+type Task struct {
+	r       *Renderer
+	label   string
+	started time.Time
+	tokens  int
+	status  string
+}
+
+// New starts a renderer on stdout for about total tasks (0 if unknown).
+func New(title string, total int) *Renderer {
+	r := &Renderer{
+		out:     os.Stdout,
+		live:    isTerminal(os.Stdout),
+		width:   terminalWidth(),
+		title:   title,
+		total:   total,
+		started: time.Now(),
+		quit:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if !r.live {
+		close(r.stopped)
+		return r
+	}
+
+	go func() {
+		defer close(r.stopped)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.quit:
+				return
+			case <-ticker.C:
+				r.mu.Lock()
+				r.frame++
+				r.redraw()
+				r.mu.Unlock()
+			}
+		}
+	}()
+	return r
+}
+
+// Start begins tracking a task.
+func (r *Renderer) Start(label string) *Task {
+	t := &Task{r: r, label: label, started: time.Now(), status: "waiting"}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = append(r.active, t)
+	if r.live {
+		r.redraw()
+	} else {
+		fmt.Fprintf(r.out, "→ %s\n", label)
+	}
+	return t
+}
+
+// Println prints a line above the live block, so messages and progress do not overwrite each other.
+func (r *Renderer) Println(a ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clear()
+	fmt.Fprintln(r.out, a...)
+	r.redraw()
+}
+
+// Stop removes the live block and prints a line with the totals.
+func (r *Renderer) Stop() {
+	if r.live {
+		close(r.quit)
+	}
+	<-r.stopped
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clear()
+	tokens := r.tokens
+	for _, t := range r.active {
+		tokens += t.tokens
+	}
+	fmt.Fprintf(r.out, "%s: %d done, %d failed, %s tokens received in %s\n",
+		r.title, r.done, r.failed, formatCount(tokens), formatDuration(time.Since(r.started)))
+}
+
+// SetStatus describes what the task is doing, e.g. "generating" or "retrying".
+func (t *Task) SetStatus(status string) {
+	if t == nil {
+		return
+	}
+	t.r.mu.Lock()
+	t.status = status
+	t.r.mu.Unlock()
+}
+
+// SetTokens records the total tokens received so far, as reported by the provider.
+func (t *Task) SetTokens(n int) {
+	if t == nil {
+		return
+	}
+	t.r.mu.Lock()
+	t.tokens = n
+	t.status = "receiving"
+	t.r.mu.Unlock()
+}
+
+// AddTokens adds estimated tokens for a chunk when the provider reports no count.
+func (t *Task) AddTokens(n int) {
+	if t == nil {
+		return
+	}
+	t.r.mu.Lock()
+	t.tokens += n
+	t.status = "receiving"
+	t.r.mu.Unlock()
+}
+
+// Done finishes the task, successfully when err is nil.
+func (t *Task) Done(err error) {
+	if t == nil {
+		return
+	}
+	r := t.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, a := range r.active {
+		if a == t {
+			r.active = append(r.active[:i], r.active[i+1:]...)
+			break
+		}
+	}
+	r.tokens += t.tokens
+	line := fmt.Sprintf("✓ %s (%s tokens, %s)", t.label, formatCount(t.tokens), formatDuration(time.Since(t.started)))
+	if err != nil {
+		r.failed++
+		line = fmt.Sprintf("✖ %s: %v", t.label, err)
+	} else {
+		r.done++
+	}
+
+	r.clear()
+	fmt.Fprintln(r.out, line)
+	r.redraw()
+}
+
+type taskKey struct{}
+
+// WithTask returns a context carrying t, for providers to report progress to.
+func WithTask(ctx context.Context, t *Task) context.Context {
+	return context.WithValue(ctx, taskKey{}, t)
+}
+
+// FromContext returns the task carried by ctx, or nil.
+func FromContext(ctx context.Context) *Task {
+	t, _ := ctx.Value(taskKey{}).(*Task)
+	return t
+}
+
+// redraw paints the live block. The caller holds r.mu.
+func (r *Renderer) redraw() {
+	if !r.live {
+		return
+	}
+	r.clear()
+
+	var lines []string
+	now := time.Now()
+	tokens := r.tokens
+	for i, t := range r.active {
+		tokens += t.tokens
+		if i < maxLiveTasks {
+			lines = append(lines, fmt.Sprintf("%s %s  %s  %s tokens  %s",
+				spinner[r.frame%len(spinner)], t.label, t.status, formatCount(t.tokens), formatDuration(now.Sub(t.started))))
+		}
+	}
+	if n := len(r.active) - maxLiveTasks; n > 0 {
+		lines = append(lines, fmt.Sprintf("  … and %d more", n))
+	}
+
+	count := strconv.Itoa(r.done + r.failed)
+	if r.total > 0 {
+		count += "/" + strconv.Itoa(r.total)
+	}
+	status := fmt.Sprintf("%s: %s done", r.title, count)
+	if r.failed > 0 {
+		status += fmt.Sprintf(", %d failed", r.failed)
+	}
+	lines = append(lines, fmt.Sprintf("%s · %s tokens · %s", status, formatCount(tokens), formatDuration(now.Sub(r.started))))
+
+	for _, l := range lines {
+		fmt.Fprintln(r.out, fit(l, r.width))
+	}
+	r.drawn = len(lines)
+}
+
+// clear erases the live block. The caller holds r.mu.
+func (r *Renderer) clear() {
+	if r.drawn > 0 {
+		fmt.Fprintf(r.out, "\x1b[%dF\x1b[J", r.drawn) // Up to the block's first line, then erase below.
+		r.drawn = 0
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal that understands cursor movement.
+func isTerminal(f *os.File) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns $COLUMNS, or 80.
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
+
+// fit cuts s to fewer than width runes, so no line wraps and the block's height stays known.
+func fit(s string, width int) string {
+	width = max(width, 2) // Room for at least the ellipsis on very narrow terminals.
+	if r := []rune(s); len(r) >= width {
+		return string(r[:width-2]) + "…"
+	}
+	return s
+}
+
+// formatCount renders n compactly, e.g. "950" or "12.4k".
+func formatCount(n int) string {
+	if n < 1000 {
+		return strconv.Itoa(n)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000), ".0") + "k"
+}
+
+// formatDuration renders d as seconds below a minute and as m:ss above.
+func formatDuration(d time.Duration) string {
+	s := int(d.Seconds())
+	if s < 60 {
+		return fmt.Sprintf("%ds", s)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -11,8 +11,23 @@ import (
 )
 
 // BuildFileContextPrompt constructs a prompt for analyzing the context of a file.
+// Go files get their statically extracted facts embedded so the model only has to summarize.
 func BuildFileContextPrompt(path string, content string) string {
-	return fmt.Sprintf(TemplateFileContext, path, content) // Uses a predefined template to format the prompt.
+	static, err := contextstore.StaticDetails(path, content)
+	if err != nil {
+		return fmt.Sprintf(TemplateFileContext, path, content) // Uses a predefined template to format the prompt.
+	}
+
+	facts, err := json.Marshal(struct {
+		Package   string                `json:"package"`
+		BuildTags string                `json:"build_tags,omitempty"`
+		Imports   []string              `json:"imports"`
+		Exports   []contextstore.Export `json:"exports"`
+	}{static.Package, static.BuildTags, static.Imports, static.Exports})
+	if err != nil {
+		return fmt.Sprintf(TemplateFileContext, path, content)
+	}
+	return fmt.Sprintf(TemplateGoFileContext, path, facts, content)
 }
 
 // BuildCommentPrompt constructs a prompt for generating code comments based on style and context.
@@ -53,21 +68,64 @@ func BuildFixesPrompt(original string, aiOutput string) string {
 }
 
 // BuildReadmePrompt constructs a prompt for generating a project README file.
-func BuildReadmePrompt(contexts []contextstore.FileDetails, existingReadme string, fileTree string) (string, error) {
+func BuildReadmePrompt(contexts contextstore.Hierarchy, existingReadme string, fileTree string) (string, error) {
+	contextStr, err := EncodeHierarchy(contexts) // Module, package and file context as JSON lines.
+	if err != nil {
+		return "", err
+	}
+
+	readmeStr := strings.TrimSpace(existingReadme) // Removes leading/trailing whitespace from existing README.
+	treeStr := strings.TrimSpace(fileTree)         // Removes leading/trailing whitespace from file tree.
+
+	return fmt.Sprintf(TemplateReadme, contextStr, treeStr, readmeStr), nil // Formats the README prompt.
+}
+
+// BuildOverviewPrompt constructs a prompt asking for package summaries and a module overview.
+func BuildOverviewPrompt(pending contextstore.Hierarchy) (string, error) {
+	contextStr, err := EncodeHierarchy(pending)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(TemplateOverview, contextStr), nil
+}
+
+// EncodeHierarchy renders layered context as JSON lines under MODULE, PACKAGES
+// and FILES headings, from the most general tier to the most specific.
+func EncodeHierarchy(h contextstore.Hierarchy) (string, error) {
 	var sb strings.Builder // Efficiently builds the string for contexts.
 
-	for _, c := range contexts {
-		j, err := json.Marshal(c) // Marshals each file detail into JSON.
+	writeLine := func(v any) error {
+		j, err := json.Marshal(v) // Marshals each entry into JSON.
 		if err != nil {
-			return "", fmt.Errorf("context marshal error: %w", err) // Returns error if marshaling fails.
+			return fmt.Errorf("context marshal error: %w", err) // Returns error if marshaling fails.
 		}
 		sb.Write(j)
 		sb.WriteByte('\n') // Adds a newline after each JSON object.
+		return nil
 	}
 
-	contextStr := sb.String()                      // Converts the builder content to a string.
-	readmeStr := strings.TrimSpace(existingReadme) // Removes leading/trailing whitespace from existing README.
-	treeStr := strings.TrimSpace(fileTree)         // Removes leading/trailing whitespace from file tree.
+	if h.Module != nil {
+		sb.WriteString("MODULE:\n")
+		if err := writeLine(h.Module); err != nil {
+			return "", err
+		}
+	}
+	if len(h.Packages) > 0 {
+		sb.WriteString("PACKAGES:\n")
+		for _, p := range h.Packages {
+			if err := writeLine(p); err != nil {
+				return "", err
+			}
+		}
+	}
+	if len(h.Files) > 0 {
+		sb.WriteString("FILES:\n")
+		for _, f := range h.Files {
+			if err := writeLine(f); err != nil {
+				return "", err
+			}
+		}
+	}
 
-	return fmt.Sprintf(TemplateReadme, contextStr, treeStr, readmeStr), nil // Formats the README prompt.
+	return sb.String(), nil
 }
@@ -23,3 +23,26 @@ Path:
 Content:
 %s
 `
+
+const TemplateGoFileContext = `
+Summarize the Go file and output a single JSON object with exactly these fields (no extras):
+
+* path: string, copied exactly from Path below
+* summary: short (<=50 words) summary describing the file's purpose and its runtime logic. Include important behavior only: flags and default values, file reads/writes, external calls (providers, stores, scanners, etc.), control-flow decisions, and observable side effects or error returns.
+
+Rules:
+
+1. The static facts below were extracted with go/parser and are authoritative. Use them to ground the summary.
+2. Do not list exports or imports; they are already known.
+3. Only mention identifiers that appear in the file.
+4. Return valid JSON only. Do not include explanations, commentary, code fences, or extra text.
+
+Path:
+%s
+
+Static facts (JSON):
+%s
+
+Content:
+%s
+`
@@ -0,0 +1,27 @@
+package prompt
+
+const SystemInstructionOverview = `You are a senior Go engineer documenting a codebase.
+Describe packages and modules accurately using only the provided context.
+Follow the JSON schema exactly.`
+
+const TemplateOverview = `The context below describes a Go module. MODULE holds the module entry,
+PACKAGES lists every package, and FILES lists file summaries for packages whose summary is empty.
+
+Output a JSON object with:
+
+* packages: one object per package in PACKAGES whose summary is empty, with
+  - path: the package path, copied exactly
+  - summary: <=60 words on what the package is for, its main types and functions, and how other packages use it
+* module: object with
+  - summary: <=120 words describing what the module does and how its packages fit together
+
+Rules:
+
+1. Use the package doc comment when present; it states the author's intent.
+2. Base the module summary on all package summaries, including the ones you write.
+3. Do not invent functionality that is not in the context.
+4. Return valid JSON only.
+
+Context:
+%s
+`
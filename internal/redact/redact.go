@@ -0,0 +1,243 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Finding is one secret replaced by a placeholder.
+type Finding struct {
+	Source string // File or label the text came from.
+	Line   int    // 1-based line in the source, 0 for text without lines, such as a stored summary.
+	Kind   string // What was detected, e.g. "AWS access key".
+}
+
+// pattern detects one kind of secret. When the expression has a capture group,
+// only the group is redacted, so e.g. the variable name of an assignment stays.
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var patterns = []pattern{
+	{"private key", regexp.MustCompile(`-----BEGIN[A-Z ]*PRIVATE KEY-----[\s\S]*?-----END[A-Z ]*PRIVATE KEY-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"Stripe key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"API key", regexp.MustCompile(`\bsk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,}\b`)},
+	{"JWT", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`)},
+	{"URL credentials", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@"'` + "`" + `]+:([^\s@/"'` + "`" + `]{3,})@`)},
+	{"assigned secret", regexp.MustCompile(`(?i)\b[\w.-]*(?:password|passwd|pwd|secret|token|api_?key|access_?key|private_?key|credentials?)["']?\s*(?::=|=|:)\s*["'` + "`" + `]([^"'` + "`" + `\s]{6,})["'` + "`" + `]`)},
+}
+
+// standIn matches assigned values that are not secrets: words without digits,
+// e.g. YOUR_API_KEY or next_page_token, and template variables.
+var standIn = regexp.MustCompile(`^(?:[A-Za-z]+(?:[_.-][A-Za-z]+)+|<[^>]*>|\$\{.*\}|\{\{.*\}\}|%[sv])$`)
+
+// quoted matches the contents of string literals, where high-entropy secrets live.
+var quoted = regexp.MustCompile(`"((?:[^"\\\n]|\\.)*)"|'((?:[^'\\\n]|\\.)*)'|` + "`([^`]*)`")
+
+// token matches runs of characters that keys and tokens are made of.
+var token = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+
+// placeholder matches the placeholders Redact produces.
+var placeholder = regexp.MustCompile(`REDACTED_SECRET_[0-9a-f]{12}`)
+
+// Redactor replaces secrets in text with placeholders and puts them back in
+// responses. The placeholder for a secret is derived from its value, so it is
+// the same in every request and every run, and the response cache still hits.
+// It is safe for concurrent use.
+type Redactor struct {
+	mu       sync.Mutex
+	secrets  map[string]string // Placeholder to the secret it replaced.
+	findings []Finding
+	seen     map[Finding]bool
+}
+
+// New returns an empty Redactor.
+func New() *Redactor {
+	return &Redactor{secrets: map[string]string{}, seen: map[Finding]bool{}}
+}
+
+// Redact returns text with every detected secret replaced and records where
+// each was found in source. line is the line of source that text starts at, or
+// 0 if text does not come from a file's lines.
+func (r *Redactor) Redact(source string, line int, text string) string {
+	spans := detect(text)
+	if len(spans) == 0 {
+		return text
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		secret := text[s.start:s.end]
+		sum := sha256.Sum256([]byte(secret))
+		ph := "REDACTED_SECRET_" + hex.EncodeToString(sum[:6])
+		r.secrets[ph] = secret
+
+		f := Finding{Source: source, Kind: s.kind}
+		if line > 0 {
+			f.Line = line + strings.Count(text[:s.start], "\n")
+		}
+		if !r.seen[f] {
+			r.seen[f] = true
+			r.findings = append(r.findings, f)
+		}
+
+		b.WriteString(text[last:s.start])
+		b.WriteString(ph)
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Restore puts back the secrets behind any placeholders in text.
+func (r *Redactor) Restore(text string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return placeholder.ReplaceAllStringFunc(text, func(ph string) string {
+		if secret, ok := r.secrets[ph]; ok {
+			return secret
+		}
+		return ph
+	})
+}
+
+// Findings returns every redaction made so far, sorted by source and line.
+func (r *Redactor) Findings() []Finding {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := append([]Finding(nil), r.findings...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Source != out[j].Source {
+			return out[i].Source < out[j].Source
+		}
+		return out[i].Line < out[j].Line
+	})
+	return out
+}
+
+type span struct {
+	start, end int
+	kind       string
+}
+
+// detect returns the non-overlapping spans of text that hold secrets, in order.
+// Of overlapping spans the first to start wins, and known patterns win ties with
+// high-entropy strings.
+func detect(text string) []span {
+	var spans []span
+	for _, p := range patterns {
+		for _, m := range p.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if len(m) > 2 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if p.kind == "assigned secret" && standIn.MatchString(text[start:end]) {
+				continue
+			}
+			spans = append(spans, span{start, end, p.kind})
+		}
+	}
+	for _, m := range quoted.FindAllStringSubmatchIndex(text, -1) {
+		for g := 2; g < len(m); g += 2 {
+			if m[g] < 0 {
+				continue
+			}
+			lit := text[m[g]:m[g+1]]
+			for _, t := range token.FindAllStringIndex(lit, -1) {
+				if highEntropy(lit[t[0]:t[1]]) {
+					spans = append(spans, span{m[g] + t[0], m[g] + t[1], "high-entropy string"})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var out []span
+	for _, s := range spans {
+		if placeholder.MatchString(text[s.start:s.end]) {
+			continue // Already redacted, e.g. a retried request.
+		}
+		if n := len(out); n > 0 && s.start < out[n-1].end {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// highEntropy reports whether s looks like a random key rather than a word,
+// identifier or path: it mixes letters and digits, and its characters are
+// spread evenly enough. Hex strings need more length, having fewer symbols.
+func highEntropy(s string) bool {
+	if isPath(s) {
+		return false
+	}
+	var lower, upper, digit bool
+	hexOnly := true
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+			if c > 'f' {
+				hexOnly = false
+			}
+		case c >= 'A' && c <= 'Z':
+			upper = true
+			hexOnly = false
+		case c >= '0' && c <= '9':
+			digit = true
+		default:
+			hexOnly = false
+		}
+	}
+	if !digit || !(lower || upper) {
+		return false
+	}
+	if hexOnly {
+		return len(s) >= 32 && entropy(s) >= 3.0
+	}
+	return lower && upper && entropy(s) >= 4.0
+}
+
+// isPath reports whether s reads as a slash-separated path or resource name,
+// such as projects/123/locations/us-central1, whose segments are all short.
+// Slashes in base64 keys leave long runs between them.
+func isPath(s string) bool {
+	if !strings.Contains(s, "/") {
+		return false
+	}
+	for _, seg := range strings.Split(s, "/") {
+		if len(seg) >= 16 {
+			return false
+		}
+	}
+	return true
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, c := range s {
+		counts[c]++
+	}
+	n := float64(len(s))
+	h := 0.0
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -1,20 +1,53 @@
 package scanner
 
-func BatchByLines(files []Info, maxLines int) [][]Info {
-	var result [][]Info
-	var group []Info
-	used := 0 // Tracks the total lines in the current group
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/praneeth-ayla/autocommenter/internal/gosrc"
+)
+
+// BatchLimits bounds the estimated size of one batched request.
+type BatchLimits struct {
+	MaxInputTokens  int // Prompt tokens per request.
+	MaxOutputTokens int // Expected response tokens per request.
+}
+
+// DefaultBatchLimits keeps requests well inside current model windows while
+// still packing many small files together.
+var DefaultBatchLimits = BatchLimits{MaxInputTokens: 24000, MaxOutputTokens: 8000}
+
+// TokenCost estimates the prompt and response tokens a file adds to a request.
+type TokenCost func(d Data) (input, output int)
+
+// BatchByTokens packs files into batches whose estimated input and output stay
+// within limits. A file too large for a request on its own is split into parts
+// at declaration boundaries, and each part is sent in a batch of its own so
+// results map back to it unambiguously.
+func BatchByTokens(files []Data, limits BatchLimits, cost TokenCost) [][]Data {
+	var result [][]Data
+	var group []Data
+	usedIn, usedOut := 0, 0 // Tracks the estimated tokens in the current group
 
 	for _, f := range files {
-		// If adding the current file exceeds maxLines and the group is not empty,
+		in, out := cost(f)
+		if in > limits.MaxInputTokens || out > limits.MaxOutputTokens {
+			for _, part := range SplitFile(f, limits, cost) {
+				result = append(result, []Data{part})
+			}
+			continue
+		}
+
+		// If adding the current file exceeds a limit and the group is not empty,
 		// finalize the current group and start a new one.
-		if used+f.Lines > maxLines && len(group) > 0 {
+		if (usedIn+in > limits.MaxInputTokens || usedOut+out > limits.MaxOutputTokens) && len(group) > 0 {
 			result = append(result, group)
 			group = nil
-			used = 0
+			usedIn, usedOut = 0, 0
 		}
 		group = append(group, f)
-		used += f.Lines
+		usedIn += in
+		usedOut += out
 	}
 
 	// Add the last group if it's not empty.
@@ -23,4 +56,99 @@ func BatchByLines(files []Info, maxLines int) [][]Info {
 	}
 
 	return result
-}
\ No newline at end of file
+}
+
+// SplitFile cuts a file into parts that each fit limits. Go files are cut between
+// top-level declarations, other files at blank lines; a single declaration that
+// is still too large is cut between lines. A file that fits is returned whole.
+func SplitFile(f Data, limits BatchLimits, cost TokenCost) []Data {
+	if in, out := cost(f); in <= limits.MaxInputTokens && out <= limits.MaxOutputTokens {
+		return []Data{f}
+	}
+
+	// A part costs about what an empty file does plus what each of its lines
+	// adds, so every line is estimated once and any range is sized from running
+	// totals. A line is rounded up by a token, as estimators round the whole text.
+	lines := strings.SplitAfter(f.Content, "\n")
+	baseIn, baseOut := cost(Data{Path: f.Path})
+	sumIn := make([]int, len(lines)+1)
+	sumOut := make([]int, len(lines)+1)
+	for i, line := range lines {
+		in, out := cost(Data{Path: f.Path, Content: line})
+		sumIn[i+1] = sumIn[i] + max(in-baseIn, 0) + 1
+		sumOut[i+1] = sumOut[i] + max(out-baseOut, 0)
+	}
+	estimate := func(from, to int) bool {
+		return baseIn+sumIn[to]-sumIn[from] <= limits.MaxInputTokens && baseOut+sumOut[to]-sumOut[from] <= limits.MaxOutputTokens
+	}
+	fits := func(from, to int) bool {
+		in, out := cost(Data{Path: f.Path, Content: strings.Join(lines[from:to], "")})
+		return in <= limits.MaxInputTokens && out <= limits.MaxOutputTokens
+	}
+
+	// Parts may end between segments, which run between cut points, or between
+	// any two lines of a segment too large to fit on its own.
+	var ends []int
+	prev := 0
+	for _, next := range append(cutPoints(f, lines), len(lines)) {
+		if next <= prev {
+			continue
+		}
+		if !estimate(prev, next) {
+			for line := prev + 1; line < next; line++ {
+				ends = append(ends, line)
+			}
+		}
+		ends = append(ends, next)
+		prev = next
+	}
+
+	// Each part runs to the furthest end estimated to fit, moved back while the
+	// full cost says otherwise. A part always takes at least its first end.
+	var bounds [][2]int
+	start := 0
+	for i := 0; i < len(ends); {
+		j := i
+		for j+1 < len(ends) && estimate(start, ends[j+1]) {
+			j++
+		}
+		for j > i && !fits(start, ends[j]) {
+			j--
+		}
+		bounds = append(bounds, [2]int{start, ends[j]})
+		start, i = ends[j], j+1
+	}
+
+	parts := make([]Data, len(bounds))
+	for i, b := range bounds {
+		parts[i] = Data{
+			Path:    f.Path,
+			Content: strings.Join(lines[b[0]:b[1]], ""),
+			Part:    i + 1,
+			Parts:   len(bounds),
+			Line:    b[0] + 1,
+		}
+	}
+	return parts
+}
+
+// cutPoints returns 0-based line indexes where a file may be split.
+func cutPoints(f Data, lines []string) []int {
+	if filepath.Ext(f.Path) == ".go" {
+		if starts, err := gosrc.DeclStarts(f.Path, []byte(f.Content)); err == nil && len(starts) > 0 {
+			cuts := make([]int, len(starts))
+			for i, line := range starts {
+				cuts[i] = line - 1
+			}
+			return cuts
+		}
+	}
+
+	var cuts []int
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			cuts = append(cuts, i+1) // Cut after the blank line.
+		}
+	}
+	return cuts
+}
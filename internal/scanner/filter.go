@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FilterRules configures which scanned files are worth generating comments for.
+type FilterRules struct {
+	Include      []string // If set, a file must match at least one of these globs.
+	Exclude      []string // Files matching any of these globs are skipped.
+	IncludeTests bool     // Keep _test.go files instead of skipping them.
+}
+
+// Decision records whether a file passed the filter and why.
+type Decision struct {
+	File     Info
+	Rel      string // Path relative to the project root, slash separated.
+	Included bool
+	Reason   string
+}
+
+// defaultExcludes are skipped regardless of project configuration.
+var defaultExcludes = []string{
+	"vendor/",
+	"testdata/",
+	"*.d.ts",
+	"__tests__/",
+	".storybook/",
+	"next.config.*",
+}
+
+// generatedRe matches the standard header described at https://go.dev/s/generatedcode.
+var generatedRe = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// FilterFilesNeedingComments returns only the comment-worthy files.
+func FilterFilesNeedingComments(root string, files []Info, rules FilterRules) []Info {
+	var result []Info
+	for _, d := range ExplainFilter(root, files, rules) {
+		if d.Included {
+			result = append(result, d.File)
+		}
+	}
+	return result
+}
+
+// ExplainFilter evaluates every file against the rules and returns one decision per file.
+func ExplainFilter(root string, files []Info, rules FilterRules) []Decision {
+	decisions := make([]Decision, 0, len(files))
+	for _, f := range files {
+		rel := RelSlash(root, f.Path)
+		included, reason := evaluate(f, rel, rules)
+		decisions = append(decisions, Decision{File: f, Rel: rel, Included: included, Reason: reason})
+	}
+	return decisions
+}
+
+// evaluate applies built-in skips first, then exclude rules, then include rules.
+func evaluate(f Info, rel string, rules FilterRules) (bool, string) {
+	for _, pattern := range defaultExcludes {
+		if MatchGlob(pattern, rel) {
+			return false, "built-in exclude " + pattern
+		}
+	}
+
+	if !rules.IncludeTests && strings.HasSuffix(rel, "_test.go") {
+		return false, "test file (set filter.include_tests to keep)"
+	}
+
+	if filepath.Ext(rel) == ".go" && isGenerated(f.Path) {
+		return false, "generated file (Code generated ... DO NOT EDIT.)"
+	}
+
+	for _, pattern := range rules.Exclude {
+		if MatchGlob(pattern, rel) {
+			return false, "matches exclude " + pattern
+		}
+	}
+
+	if len(rules.Include) == 0 {
+		return true, "no include rules"
+	}
+	for _, pattern := range rules.Include {
+		if MatchGlob(pattern, rel) {
+			return true, "matches include " + pattern
+		}
+	}
+	return false, "matches no include rule"
+}
+
+// isGenerated reports whether the file carries the generated-code marker before its package clause.
+func isGenerated(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024) // Allow long lines in generated sources.
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if generatedRe.MatchString(line) {
+			return true
+		}
+		if strings.HasPrefix(line, "package ") {
+			break // The marker must precede the package clause to count.
+		}
+	}
+	return false
+}
+
+// RelSlash returns path relative to root using forward slashes, or path itself if that fails.
+func RelSlash(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// KeepPaths returns the files whose path relative to root is in rels.
+func KeepPaths(root string, files []Info, rels []string) []Info {
+	keep := make(map[string]bool, len(rels))
+	for _, r := range rels {
+		keep[filepath.ToSlash(r)] = true
+	}
+
+	var result []Info
+	for _, f := range files {
+		if keep[RelSlash(root, f.Path)] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether the slash-separated relative path rel matches pattern.
+// Patterns follow path.Match syntax per segment with two additions:
+// "**" matches any number of segments, and a pattern without a slash
+// matches the base name at any depth. A trailing slash matches everything
+// below a directory of that name.
+func MatchGlob(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return false
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		dir := strings.TrimSuffix(pattern, "/")
+		if !strings.Contains(dir, "/") {
+			dir = "**/" + dir // Bare directory names match at any depth.
+		}
+		pattern = dir + "/**"
+	}
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches pattern segments against path segments, expanding "**".
+func matchSegments(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			pat = pat[1:]
+			if len(pat) == 0 {
+				return true // Trailing "**" swallows the rest of the path.
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pat, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
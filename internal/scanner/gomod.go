@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// blockCommentRe matches /* ... */ comments, which go.mod permits anywhere.
+var blockCommentRe = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// ModulePath reads the module path declared in root/go.mod.
+func ModulePath(root string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	return ParseModulePath(string(data))
+}
+
+// ParseModulePath extracts the module path from go.mod content. Like
+// golang.org/x/mod/modfile it tolerates comments before or after the
+// directive, quoted paths and the factored "module ( path )" form.
+func ParseModulePath(gomod string) (string, error) {
+	gomod = blockCommentRe.ReplaceAllString(gomod, "")
+
+	inBlock := false
+	for _, line := range strings.Split(gomod, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i] // Drop trailing comments such as "// Deprecated: ...".
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBlock {
+			if fields[0] == ")" {
+				break
+			}
+			return unquoteModulePath(fields[0])
+		}
+
+		if fields[0] != "module" {
+			continue
+		}
+		switch {
+		case len(fields) == 1:
+			return "", fmt.Errorf("go.mod: module directive without a path")
+		case fields[1] == "(":
+			inBlock = true
+		default:
+			return unquoteModulePath(fields[1])
+		}
+	}
+
+	return "", fmt.Errorf("go.mod: no module directive found")
+}
+
+// unquoteModulePath removes Go string quoting from a module path if present.
+func unquoteModulePath(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`") {
+		unq, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("go.mod: invalid quoted module path %s", s)
+		}
+		s = unq
+	}
+	if s == "" {
+		return "", fmt.Errorf("go.mod: empty module path")
+	}
+	return s, nil
+}
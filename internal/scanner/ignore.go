@@ -0,0 +1,202 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// IgnoreFileName is the tool-specific ignore file read from the project root.
+const IgnoreFileName = ".autocommenterignore"
+
+// Ignorer decides whether paths below a root are ignored. It follows gitignore
+// semantics for the root and nested .gitignore files, .git/info/exclude and
+// .autocommenterignore on top of defaultIgnore, and always skips the directories
+// in skipDirs.
+type Ignorer struct {
+	root string
+
+	mu     sync.Mutex
+	nested map[string][]ignoreRule // Rules from each directory's .gitignore, keyed by relative dir.
+	dirs   map[string]bool         // Cached ignore results for directories.
+
+	base  []ignoreRule // Rules from defaultIgnore and .git/info/exclude, lowest precedence.
+	extra []ignoreRule // Rules from .autocommenterignore, highest precedence.
+}
+
+type ignoreRule struct {
+	base     string // Directory the rule was declared in, relative to the root.
+	pattern  string
+	negate   bool // Pattern started with "!" and re-includes matches.
+	dirOnly  bool // Pattern ended with "/" and only matches directories.
+	anchored bool // Pattern contained a slash and is relative to base.
+}
+
+// NewIgnorer loads the ignore files found at root. Nested .gitignore files are read lazily.
+func NewIgnorer(root string) *Ignorer {
+	return &Ignorer{
+		root:   root,
+		nested: make(map[string][]ignoreRule),
+		dirs:   make(map[string]bool),
+		base:   append(defaultRules(), readIgnoreFile(filepath.Join(root, ".git", "info", "exclude"), "")...),
+		extra:  readIgnoreFile(filepath.Join(root, IgnoreFileName), ""),
+	}
+}
+
+// Ignored reports whether the slash-separated relative path should be skipped.
+// A path is ignored when it matches itself or when any parent directory is ignored.
+func (ig *Ignorer) Ignored(rel string, isDir bool) bool {
+	rel = strings.Trim(filepath.ToSlash(rel), "/")
+	if rel == "" || rel == "." {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if ig.dirIgnored(strings.Join(parts[:i], "/")) {
+			return true
+		}
+	}
+	if isDir {
+		return ig.dirIgnored(rel)
+	}
+	return ig.match(rel, false)
+}
+
+// dirIgnored caches directory results, since every file below a directory asks again.
+func (ig *Ignorer) dirIgnored(rel string) bool {
+	ig.mu.Lock()
+	ignored, ok := ig.dirs[rel]
+	ig.mu.Unlock()
+	if ok {
+		return ignored
+	}
+
+	ignored = skipDirs[path.Base(rel)] || ig.match(rel, true)
+
+	ig.mu.Lock()
+	ig.dirs[rel] = ignored
+	ig.mu.Unlock()
+	return ignored
+}
+
+// match evaluates rules in precedence order; the last matching rule wins.
+func (ig *Ignorer) match(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range ig.rulesFor(rel) {
+		if r.matches(rel, isDir) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// rulesFor returns every rule that may apply to rel, from lowest to highest precedence.
+func (ig *Ignorer) rulesFor(rel string) []ignoreRule {
+	rules := append([]ignoreRule{}, ig.base...)
+	rules = append(rules, ig.gitignore("")...)
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		rules = append(rules, ig.gitignore(strings.Join(parts[:i], "/"))...)
+	}
+
+	return append(rules, ig.extra...)
+}
+
+// gitignore returns the rules of the .gitignore file in dir, reading it on first use.
+func (ig *Ignorer) gitignore(dir string) []ignoreRule {
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+
+	if rules, ok := ig.nested[dir]; ok {
+		return rules
+	}
+	rules := readIgnoreFile(filepath.Join(ig.root, filepath.FromSlash(dir), ".gitignore"), dir)
+	ig.nested[dir] = rules
+	return rules
+}
+
+// matches reports whether the rule matches rel (relative to the ignorer root).
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	sub := rel
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false // Nested rules only apply below their own directory.
+		}
+		sub = strings.TrimPrefix(rel, r.base+"/")
+	}
+
+	if r.anchored {
+		return matchSegments(strings.Split(r.pattern, "/"), strings.Split(sub, "/"))
+	}
+	ok, _ := path.Match(r.pattern, path.Base(sub))
+	return ok
+}
+
+// readIgnoreFile parses a gitignore-style file. Missing files yield no rules.
+func readIgnoreFile(file, base string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if r, ok := parseIgnoreLine(sc.Text(), base); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// defaultRules parses defaultIgnore.
+func defaultRules() []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range defaultIgnore {
+		if r, ok := parseIgnoreLine(line, ""); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine converts one gitignore line into a rule, skipping blanks and comments.
+func parseIgnoreLine(line, base string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	r := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // Escaped leading "#" or "!".
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	r.pattern = line
+	return r, true
+}
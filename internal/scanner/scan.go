@@ -7,19 +7,19 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 )
 
+// skipDirs are directory names skipped at any depth; ignore files cannot bring them back.
 var skipDirs = map[string]bool{
-	"node_modules": true,
-	".git":         true,
-	".next":        true,
-	"build":        true,
-	"dist":         true,
-	"migrations":   true,
-	"prisma":       true,
+	"node_modules":   true,
+	".git":           true,
+	".autocommenter": true, // Project context and run state, never source.
 }
 
+// defaultIgnore holds the lowest-precedence ignore rules: build output and
+// generated code in the root, which a "!" pattern in an ignore file re-includes.
+var defaultIgnore = []string{"/.next/", "/build/", "/dist/", "/migrations/", "/prisma/"}
+
 var allowedExt = map[string]bool{
 	".ts":  true,
 	".tsx": true,
@@ -37,6 +37,7 @@ func Scan(root string) ([]Info, error) {
 	}
 
 	var files []Info
+	ignorer := NewIgnorer(abs)
 
 	err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -45,15 +46,19 @@ func Scan(root string) ([]Info, error) {
 			return nil
 		}
 
-		if d.IsDir() {
-			rel, _ := filepath.Rel(abs, path)
-			if skipDirs[rel] {
+		rel, _ := filepath.Rel(abs, path)
+		if ignorer.Ignored(rel, d.IsDir()) {
+			if d.IsDir() {
 				// Skip this directory and its contents
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
+		if d.IsDir() {
+			return nil
+		}
+
 		ext := filepath.Ext(path)
 		if !allowedExt[ext] {
 			// Skip files with disallowed extensions
@@ -88,34 +93,6 @@ func Scan(root string) ([]Info, error) {
 	return files, nil
 }
 
-// Filter only returns comment-worthy files
-func FilterFilesNeedingComments(files []Info) []Info {
-	var result []Info
-
-	for _, f := range files {
-		p := f.Path
-
-		if strings.HasSuffix(p, ".d.ts") {
-			continue // Skip declaration files
-		}
-		if strings.Contains(p, "/ui/") ||
-			strings.Contains(p, "/types/") ||
-			strings.Contains(p, "/__tests__/") ||
-			strings.Contains(p, "/.storybook/") {
-			continue // Skip files in specific directories
-		}
-		if strings.HasPrefix(p, "next-env.d.ts") ||
-			strings.HasPrefix(p, "next.config") ||
-			strings.Contains(p, "seed.") {
-			continue // Skip specific files or files containing specific patterns
-		}
-
-		result = append(result, f)
-	}
-
-	return result
-}
-
 func countLines(path string) int {
 	file, err := os.Open(path)
 	if err != nil {
@@ -135,4 +112,4 @@ func countLines(path string) int {
 		}
 	}
 	return count
-}
\ No newline at end of file
+}
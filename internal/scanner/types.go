@@ -1,5 +1,7 @@
 package scanner
 
+import "fmt"
+
 // Info represents metadata for a scanned file.
 type Info struct {
 	Path  string `json:"path"`
@@ -11,4 +13,15 @@ type Info struct {
 type Data struct {
 	Path    string
 	Content string
+	Part    int // 1-based position when the file was split by SplitFile, 0 when whole.
+	Parts   int // Number of parts the file was split into.
+	Line    int // 1-based line of the file that a part starts at, 0 when whole.
+}
+
+// Label names the file for prompts, marking parts of a split file.
+func (d Data) Label() string {
+	if d.Parts > 1 {
+		return fmt.Sprintf("%s (part %d of %d)", d.Path, d.Part, d.Parts)
+	}
+	return d.Path
 }
\ No newline at end of file
@@ -3,6 +3,13 @@ package scanner
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	writeLogMu sync.Mutex
+	writeLog   []string // Absolute paths of generated files written by WriteFile during this run.
 )
 
 // WriteFile writes the given content to the specified file path.
@@ -14,6 +21,67 @@ func WriteFile(filePath, content string) error {
 		// Wrap the error with a more descriptive message.
 		return fmt.Errorf("failed to write file: %w", err)
 	}
+	recordWrite(filePath)
 	// Return nil to indicate success.
 	return nil
-}
\ No newline at end of file
+}
+
+// WriteFileAtomic writes content to a temporary file next to filePath and renames
+// it into place, so readers and concurrent writers never see a partial file.
+// It is meant for autocommenter's own state, such as the context store, so
+// unlike WriteFile it leaves the write log alone.
+func WriteFileAtomic(filePath, content string) error {
+	f, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	tmp := f.Name()
+
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := f.Sync(); err != nil { // Make sure the data is on disk before it replaces the old file.
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Chmod(tmp, 0644); err != nil { // CreateTemp makes the file private.
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp, filePath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Written returns the paths written by WriteFile so far, in write order and without duplicates.
+func Written() []string {
+	writeLogMu.Lock()
+	defer writeLogMu.Unlock()
+	return append([]string(nil), writeLog...)
+}
+
+// recordWrite appends a path to the write log unless it is already there.
+func recordWrite(filePath string) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		abs = filePath
+	}
+
+	writeLogMu.Lock()
+	defer writeLogMu.Unlock()
+	for _, p := range writeLog {
+		if p == abs {
+			return
+		}
+	}
+	writeLog = append(writeLog, abs)
+}
@@ -0,0 +1,47 @@
+package tokens
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// Estimator estimates the number of tokens a model's tokenizer produces for text.
+type Estimator interface {
+	Estimate(text string) int
+}
+
+// Heuristic estimates tokens from character counts. ASCII text, which is most
+// source code, is divided by CharsPerToken; every other character counts as a
+// token of its own, since tokenizers rarely merge them.
+type Heuristic struct {
+	CharsPerToken float64
+}
+
+// Default is used for providers that do not supply their own estimator. It
+// assumes slightly denser tokenization than most models so batches err on the
+// small side.
+var Default Estimator = Heuristic{CharsPerToken: 3.5}
+
+// Estimate returns the estimated token count of text.
+func (h Heuristic) Estimate(text string) int {
+	if text == "" {
+		return 0
+	}
+	cpt := h.CharsPerToken
+	if cpt <= 0 {
+		cpt = 4
+	}
+
+	ascii, other := 0, 0
+	for i := 0; i < len(text); {
+		if text[i] < utf8.RuneSelf {
+			ascii++
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		other++
+		i += size
+	}
+	return int(math.Ceil(float64(ascii)/cpt)) + other
+}
@@ -0,0 +1,27 @@
+package usage
+
+import "context"
+
+// Limiter admits or refuses requests before they are sent, e.g. a run's budget.
+type Limiter interface {
+	// Reserve admits a request of estimated usage u on model. The returned func
+	// releases the reservation once the request has finished.
+	Reserve(model string, u Usage) (release func(), err error)
+}
+
+type limiterKey struct{}
+
+// WithLimiter returns a context whose requests must be admitted by l first.
+func WithLimiter(ctx context.Context, l Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, l)
+}
+
+// Reserve asks the limiter in ctx, if any, to admit a request. Providers call it
+// before every request they send, including follow-ups within one call.
+func Reserve(ctx context.Context, model string, u Usage) (func(), error) {
+	l, _ := ctx.Value(limiterKey{}).(Limiter)
+	if l == nil {
+		return func() {}, nil
+	}
+	return l.Reserve(model, u)
+}
@@ -0,0 +1,145 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Run is the usage of one command run, as kept in the run log.
+type Run struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Command   string        `json:"command"`
+	Project   string        `json:"project,omitempty"` // Project root the command ran in.
+	Usage     Usage         `json:"usage"`
+	Cost      float64       `json:"cost"` // US dollars, for the models with a known price.
+	Entries   []Entry       `json:"entries"`
+}
+
+// Entry is the usage of one file on one model within a run.
+type Entry struct {
+	File   string  `json:"file,omitempty"`
+	Model  string  `json:"model"`
+	Usage  Usage   `json:"usage"`
+	Cost   float64 `json:"cost"`
+	Priced bool    `json:"priced"` // False when the model was missing from the price table.
+}
+
+// Append adds run to the log at path, one JSON object per line.
+func Append(path string, run Run) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadLog returns the runs in the log at path that started at or after since.
+// A missing log holds no runs.
+func ReadLog(path string, since time.Time) ([]Run, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []Run
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // Runs over large projects list many files.
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var r Run
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if !r.StartedAt.Before(since) {
+			runs = append(runs, r)
+		}
+	}
+	return runs, sc.Err()
+}
+
+// Row is usage aggregated under one key of a report.
+type Row struct {
+	Key   string  `json:"key"`
+	Runs  int     `json:"runs"`
+	Usage Usage   `json:"usage"`
+	Cost  float64 `json:"cost"`
+}
+
+// Group aggregates runs by "command", "model", "file", "project" or "day".
+// Rows are sorted by cost, then key.
+func Group(runs []Run, by string) ([]Row, error) {
+	rows := map[string]*Row{}
+	add := func(key string, run int, u Usage, cost float64) {
+		r := rows[key]
+		if r == nil {
+			r = &Row{Key: key}
+			rows[key] = r
+		}
+		r.Runs += run
+		r.Usage.Add(u)
+		r.Cost += cost
+	}
+
+	for _, run := range runs {
+		switch by {
+		case "command":
+			add(run.Command, 1, run.Usage, run.Cost)
+		case "project":
+			add(run.Project, 1, run.Usage, run.Cost)
+		case "day":
+			add(run.StartedAt.Local().Format("2006-01-02"), 1, run.Usage, run.Cost)
+		case "model", "file":
+			seen := map[string]bool{} // Count each run once per key.
+			for _, e := range run.Entries {
+				key := e.Model
+				if by == "file" {
+					key = e.File
+				}
+				n := 0
+				if !seen[key] {
+					seen[key] = true
+					n = 1
+				}
+				add(key, n, e.Usage, e.Cost)
+			}
+		default:
+			return nil, fmt.Errorf("unknown grouping %q (use command, model, file, project or day)", by)
+		}
+	}
+
+	out := make([]Row, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Cost != out[j].Cost {
+			return out[i].Cost > out[j].Cost
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
@@ -0,0 +1,101 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Meter collects the usage of one command run, by file and model. It is safe
+// for concurrent use.
+type Meter struct {
+	mu      sync.Mutex
+	command string
+	started time.Time
+	entries map[entryKey]*Usage
+}
+
+type entryKey struct{ file, model string }
+
+// NewMeter starts metering a run of command, e.g. "comments gen".
+func NewMeter(command string) *Meter {
+	return &Meter{command: command, started: time.Now().UTC(), entries: map[entryKey]*Usage{}}
+}
+
+// Add records usage for file on model.
+func (m *Meter) Add(file, model string, u Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := entryKey{file, model}
+	if m.entries[k] == nil {
+		m.entries[k] = &Usage{}
+	}
+	m.entries[k].Add(u)
+}
+
+// Run returns what has been metered so far, priced with prices.
+func (m *Meter) Run(project string, prices Prices) Run {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run := Run{StartedAt: m.started, Duration: time.Since(m.started).Round(time.Millisecond), Command: m.command, Project: project}
+	for k, u := range m.entries {
+		e := Entry{File: k.file, Model: k.model, Usage: *u}
+		e.Cost, e.Priced = prices.Cost(k.model, *u)
+		run.Entries = append(run.Entries, e)
+		run.Usage.Add(*u)
+		run.Cost += e.Cost
+	}
+	sort.Slice(run.Entries, func(i, j int) bool {
+		a, b := run.Entries[i], run.Entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Model < b.Model
+	})
+	return run
+}
+
+type meterKey struct{}
+type fileKey struct{}
+
+// WithMeter returns a context whose requests are metered by m.
+func WithMeter(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// WithFile returns a context whose requests are attributed to file.
+func WithFile(ctx context.Context, file string) context.Context {
+	return context.WithValue(ctx, fileKey{}, file)
+}
+
+// FileFromContext returns the file requests in ctx are attributed to, or "".
+func FileFromContext(ctx context.Context) string {
+	file, _ := ctx.Value(fileKey{}).(string)
+	return file
+}
+
+// Record adds the usage of one response to the meter in ctx, if any. Providers
+// call it once per request.
+func Record(ctx context.Context, model string, u Usage) {
+	m, _ := ctx.Value(meterKey{}).(*Meter)
+	if m == nil {
+		return
+	}
+	m.Add(FileFromContext(ctx), model, u)
+}
+
+// Totals returns the usage metered so far and its cost at prices.
+func (m *Meter) Totals(prices Prices) (Usage, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total Usage
+	cost := 0.0
+	for k, u := range m.entries {
+		total.Add(*u)
+		c, _ := prices.Cost(k.model, *u)
+		cost += c
+	}
+	return total, cost
+}
@@ -0,0 +1,97 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Usage counts the tokens of one or more requests.
+type Usage struct {
+	Requests int `json:"requests"`
+	Prompt   int `json:"prompt_tokens"`           // Input tokens, cached ones included.
+	Cached   int `json:"cached_tokens,omitempty"` // Input tokens served from the provider's cache.
+	Output   int `json:"output_tokens"`
+	Thinking int `json:"thinking_tokens,omitempty"` // Reasoning tokens, billed as output.
+	Hits     int `json:"cache_hits,omitempty"`      // Responses served from the local response cache, without a request.
+}
+
+// Add accumulates o into u.
+func (u *Usage) Add(o Usage) {
+	u.Requests += o.Requests
+	u.Prompt += o.Prompt
+	u.Cached += o.Cached
+	u.Output += o.Output
+	u.Thinking += o.Thinking
+	u.Hits += o.Hits
+}
+
+// Total returns every token counted, input and output.
+func (u Usage) Total() int {
+	return u.Prompt + u.Output + u.Thinking
+}
+
+// Price is what a model costs in US dollars per million tokens.
+type Price struct {
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cached_input,omitempty"`
+	Output      float64 `json:"output"` // Thinking tokens are billed at this rate too.
+}
+
+// Prices maps model names to their price.
+type Prices map[string]Price
+
+// DefaultPrices are the published list prices of the models autocommenter uses,
+// for prompts under 200k tokens. Override them in the config file when they change.
+var DefaultPrices = Prices{
+	"gemini-2.5-pro":        {Input: 1.25, CachedInput: 0.31, Output: 10},
+	"gemini-2.5-flash":      {Input: 0.30, CachedInput: 0.075, Output: 2.50},
+	"gemini-2.5-flash-lite": {Input: 0.10, CachedInput: 0.025, Output: 0.40},
+}
+
+// With returns the default prices overridden by custom.
+func (p Prices) With(custom Prices) Prices {
+	merged := make(Prices, len(p)+len(custom))
+	for m, price := range p {
+		merged[m] = price
+	}
+	for m, price := range custom {
+		merged[m] = price
+	}
+	return merged
+}
+
+// Cost returns the cost of u on model, and false when the model has no price.
+func (p Prices) Cost(model string, u Usage) (float64, bool) {
+	price, ok := p[model]
+	if !ok {
+		return 0, false
+	}
+	cachedRate := price.CachedInput
+	if cachedRate == 0 {
+		cachedRate = price.Input
+	}
+	cost := float64(u.Prompt-u.Cached)*price.Input +
+		float64(u.Cached)*cachedRate +
+		float64(u.Output+u.Thinking)*price.Output
+	return cost / 1e6, true
+}
+
+// FormatCost renders a dollar amount with enough precision for small runs.
+func FormatCost(c float64) string {
+	if c < 0.01 {
+		return fmt.Sprintf("$%.4f", c)
+	}
+	return fmt.Sprintf("$%.2f", c)
+}
+
+// FormatTokens renders a token count compactly, e.g. "950" or "12.4k".
+func FormatTokens(n int) string {
+	switch {
+	case n < 1000:
+		return strconv.Itoa(n)
+	case n < 1000000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000), ".0") + "k"
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.2f", float64(n)/1000000), ".00") + "M"
+}